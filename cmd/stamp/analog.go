@@ -0,0 +1,68 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/config"
+	"github.com/toto/stamp/internal/counter"
+	"github.com/toto/stamp/internal/sequential"
+)
+
+var flagAnalogMode string
+
+// analogMode returns the counter mode from --mode, falling back to the
+// analog_counter setting.
+func analogMode(cmd *cobra.Command) (counter.Mode, error) {
+	value := cfg.AnalogCounter
+	if cmd.Flags().Changed("mode") {
+		value = flagAnalogMode
+	}
+	mode, err := config.ParseAnalogMode(value)
+	return counter.Mode(mode), err
+}
+
+// scannedAnalog returns the highest analog number for date among the entries
+// under dir, honouring the "analog" scan settings and scan flags.
+func scannedAnalog(cmd *cobra.Command, dir, date string) (int, error) {
+	spec := sequential.Spec{Prefix: date + "-A", Scan: scanOptions(cmd, "analog")}
+	return sequential.Highest(dir, spec)
+}
+
+// analogCounter returns the last analog number used on date.
+func analogCounter(cmd *cobra.Command, mode counter.Mode, dir, date string) (int, error) {
+	var stored, scanned int
+	var err error
+	if mode != counter.ModeScan {
+		if stored, err = counters.GetAnalogCounter(date); err != nil {
+			return 0, err
+		}
+	}
+	if mode != counter.ModeFile {
+		if scanned, err = scannedAnalog(cmd, dir, date); err != nil {
+			return 0, err
+		}
+	}
+	return max(stored, scanned), nil
+}
+
+// nextAnalog issues the next analog number for date. Scan mode only reads the
+// filesystem; hybrid mode also advances the counter file past any number
+// already used by a note in dir.
+func nextAnalog(cmd *cobra.Command, dir, date string) (int, error) {
+	mode, err := analogMode(cmd)
+	if err != nil {
+		return 0, err
+	}
+	if mode == counter.ModeFile {
+		return counters.NextAnalogAfter(date, 0)
+	}
+
+	scanned, err := scannedAnalog(cmd, dir, date)
+	if err != nil {
+		return 0, err
+	}
+	if mode == counter.ModeScan {
+		recordIssued("analog", counter.FormatAnalog(date, scanned+1))
+		return scanned + 1, nil
+	}
+	return counters.NextAnalogAfter(date, scanned)
+}
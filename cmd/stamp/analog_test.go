@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAnalogReportsCounter(t *testing.T) {
+	home := setupCLI(t)
+	gen.SetTime(time.Date(2025, 11, 12, 9, 30, 0, 0, time.UTC))
+	if err := os.WriteFile(filepath.Join(home, "2025-11-12-A4.md"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args        []string
+		wantID      string
+		wantCounter int
+	}{
+		{[]string{"analog", "--format", "json"}, "2025-11-12-A1", 1},
+		{[]string{"analog", "--format", "json"}, "2025-11-12-A2", 2},
+		{[]string{"analog", "--format", "json", "--mode", "scan"}, "2025-11-12-A5", 5},
+		{[]string{"analog", "--format", "json", "--mode", "hybrid"}, "2025-11-12-A5", 5},
+	}
+	for _, tt := range tests {
+		out, err := captureCLI(t, tt.args...)
+		if err != nil {
+			t.Fatalf("%v error = %v", tt.args, err)
+		}
+		var got struct {
+			ID      string `json:"id"`
+			Counter *int   `json:"counter"`
+		}
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatalf("%v output %q: %v", tt.args, out, err)
+		}
+		if got.ID != tt.wantID || got.Counter == nil || *got.Counter != tt.wantCounter {
+			t.Errorf("%v = %s, want id %s and counter %d", tt.args, out, tt.wantID, tt.wantCounter)
+		}
+	}
+}
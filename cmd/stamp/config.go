@@ -0,0 +1,309 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/config"
+)
+
+var flagConfigOrigin bool
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "View, edit and validate stamp configuration",
+	Long: `View, edit and validate configuration.
+
+Settings are read from the global file (see "stamp config path") and then
+from every .stamp.yaml found between the filesystem root and the working
+directory, so a .stamp.yaml in a repository or vault overrides the global
+file for everything below it. Relative paths in a .stamp.yaml are resolved
+against its own directory. counter_file, counter_backend and counter_server
+can only be set in the global file, so a .stamp.yaml in a cloned repository
+cannot redirect the token or choose which files hold counters.
+
+The global file is $STAMP_CONFIG, $XDG_CONFIG_HOME/stamp/config.yaml when
+XDG_CONFIG_HOME is set, or ~/.stamp/config.yaml. STAMP_TZ, STAMP_WEEK_START
+and STAMP_COUNTER_FILE override every file.
+
+Keys are written in dotted form, e.g. timezone, title.style or
+types.meeting. set and unset edit the global file only.`,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := cfg.Entries()
+		if err != nil {
+			return err
+		}
+
+		switch flagFormat {
+		case formatJSON:
+			return printConfigJSON(entries)
+		case formatEnv:
+			return fmt.Errorf("config show does not support --format env")
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, entry := range entries {
+			if flagConfigOrigin {
+				fmt.Fprintf(w, "%s: %s\t# %s\n", entry.Key, entry.Value, displayOrigin(entry.Origin))
+			} else {
+				fmt.Fprintf(w, "%s: %s\n", entry.Key, entry.Value)
+			}
+		}
+		return w.Flush()
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:          "get <key>",
+	SilenceUsage: true,
+	Short:        "Print the effective value of a key or section",
+	Args:         cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := cfg.Entries()
+		if err != nil {
+			return err
+		}
+
+		key := args[0]
+		var matched []config.Entry
+		for _, entry := range entries {
+			if entry.Key == key {
+				fmt.Println(entry.Value)
+				return nil
+			}
+			if strings.HasPrefix(entry.Key, key+".") {
+				matched = append(matched, entry)
+			}
+		}
+		if len(matched) == 0 {
+			return fmt.Errorf("config key %q is not set", key)
+		}
+		for _, entry := range matched {
+			fmt.Printf("%s: %s\n", entry.Key, entry.Value)
+		}
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:          "set <key> <value>",
+	SilenceUsage: true,
+	Short:        "Set a key in the global config file",
+	Example: `  stamp config set timezone Asia/Tokyo
+  stamp config set title.style kebab
+  stamp config set types.interview "seq prefix INT width 3"
+  stamp config set scan.P.dirs "[Archive]"`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editGlobalConfig(args[0], func(f *config.File) error {
+			return f.Set(args[0], args[1])
+		})
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:          "unset <key>",
+	SilenceUsage: true,
+	Short:        "Remove a key from the global config file, restoring its default",
+	Args:         cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return editGlobalConfig(args[0], func(f *config.File) error {
+			return f.Unset(args[0])
+		})
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the keys set in the global config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		global, err := config.LoadFile(path)
+		if err != nil {
+			return err
+		}
+		entries, err := global.Entries()
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.Origin.File != "" {
+				fmt.Printf("%s: %s\n", entry.Key, entry.Value)
+			}
+		}
+		return nil
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the location of the global config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:          "validate",
+	SilenceUsage: true,
+	Short:        "Check the global and local config files for errors",
+	Long: `Check the global config file and every .stamp.yaml that applies to the
+working directory for syntax errors, unknown keys and invalid values such as
+unknown time zones, reporting each problem with its file and line.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		files := []string{path}
+		if wd, err := os.Getwd(); err == nil {
+			files = append(files, config.FindLocal(wd)...)
+		}
+
+		total := 0
+		for i, file := range files {
+			check := config.Check
+			if i > 0 {
+				check = config.CheckLocal
+			}
+			problems, err := check(file)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			for _, problem := range problems {
+				fmt.Println(problem)
+			}
+			if len(problems) == 0 {
+				fmt.Printf("%s: ok\n", file)
+			}
+			total += len(problems)
+		}
+
+		if total > 0 {
+			return fmt.Errorf("found %d configuration problem(s)", total)
+		}
+		return nil
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:          "migrate",
+	SilenceUsage: true,
+	Short:        "Copy ~/.stamp files to the XDG config and state directories",
+	Long: `Copy config.yaml and the counter files from ~/.stamp to
+$XDG_CONFIG_HOME/stamp and $XDG_STATE_HOME/stamp when those variables are set.
+Files that already exist there are left alone, and the originals are kept in
+~/.stamp; remove them once the copies are in use. Counters are not copied
+when counter_file is set.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		notes, err := config.Migrate()
+		for _, note := range notes {
+			fmt.Println(note)
+		}
+		if err == nil && len(notes) == 0 {
+			fmt.Println("nothing to migrate")
+		}
+		return err
+	},
+}
+
+func init() {
+	configShowCmd.Flags().BoolVar(&flagConfigOrigin, "origin", false, "Show the file and line each value comes from")
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configMigrateCmd)
+}
+
+// editGlobalConfig applies edit to the global config file and saves it,
+// refusing changes that leave key with an invalid value. Only the edited key
+// changes in the file.
+func editGlobalConfig(key string, edit func(*config.File) error) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	file, err := config.OpenFile(path)
+	if err != nil {
+		return fmt.Errorf("%w (fix the file or run `stamp config validate`)", err)
+	}
+
+	if err := edit(file); err != nil {
+		return err
+	}
+	global, err := file.Config()
+	if err != nil {
+		return err
+	}
+	for _, problem := range global.Validate() {
+		if problem.Key == key || strings.HasPrefix(key, problem.Key+".") || strings.HasPrefix(problem.Key, key+".") {
+			return fmt.Errorf("%s: %s", problem.Key, problem.Message)
+		}
+	}
+	return file.Save()
+}
+
+func printConfigJSON(entries []config.Entry) error {
+	type jsonEntry struct {
+		Key    string `json:"key"`
+		Value  string `json:"value"`
+		Origin string `json:"origin,omitempty"`
+	}
+
+	records := make([]jsonEntry, 0, len(entries))
+	for _, entry := range entries {
+		record := jsonEntry{Key: entry.Key, Value: entry.Value}
+		if flagConfigOrigin {
+			record.Origin = entry.Origin.String()
+		}
+		records = append(records, record)
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// displayOrigin abbreviates the home directory to ~ for readability.
+func displayOrigin(source config.Source) string {
+	origin := source.String()
+	if home, err := os.UserHomeDir(); err == nil && source.File != "" {
+		if rel, err := filepath.Rel(home, source.File); err == nil && !strings.HasPrefix(rel, "..") {
+			origin = filepath.Join("~", rel) + strings.TrimPrefix(origin, source.File)
+		}
+	}
+	return origin
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toto/stamp/internal/config"
+)
+
+func TestConfigFixesBadTimezone(t *testing.T) {
+	setupCLI(t)
+	path, err := config.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("week_start: someday\ntimezone: Mars/Base\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loadCLI(t)
+	gen = newGenerator()
+	if loc := gen.Now().Location(); loc != time.Local {
+		t.Errorf("generator location = %v, want the local fallback", loc)
+	}
+
+	out, err := captureCLI(t, "config", "validate")
+	if err == nil {
+		t.Error("config validate error = nil, want problems")
+	}
+	for _, want := range []string{path + ":1: week_start: ", path + ":2: timezone: "} {
+		if !strings.Contains(out, want) {
+			t.Errorf("config validate =\n%s\nmissing %q", out, want)
+		}
+	}
+
+	if err := runCLI(t, "config", "set", "timezone", "UTC"); err != nil {
+		t.Fatalf("config set timezone error = %v", err)
+	}
+	if err := runCLI(t, "config", "unset", "week_start"); err != nil {
+		t.Fatalf("config unset week_start error = %v", err)
+	}
+	loadCLI(t)
+	gen = newGenerator()
+	if loc := gen.Now().Location(); loc != time.UTC {
+		t.Errorf("generator location = %v after config set, want UTC", loc)
+	}
+	if _, err := captureCLI(t, "config", "validate"); err != nil {
+		t.Errorf("config validate after the fix error = %v", err)
+	}
+}
+
+func TestConfigRunsWithoutCounters(t *testing.T) {
+	setupCLI(t)
+	counterErr = errors.New("counters.json: unexpected end of JSON input")
+	t.Cleanup(func() { counterErr = nil })
+
+	if _, err := captureCLI(t, "config", "path"); err != nil {
+		t.Errorf("config path error = %v, want it to run without counters", err)
+	}
+	if err := runCLI(t, "analog"); err == nil || !strings.Contains(err.Error(), "initializing counter") {
+		t.Errorf("analog error = %v, want the counter error", err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/config"
+	"github.com/toto/stamp/internal/counter"
+)
+
+var (
+	flagPruneOlderThan string
+	flagPruneDryRun    bool
+)
+
+var countersCmd = &cobra.Command{
+	Use:   "counters",
+	Short: "Maintain the analog counter store",
+}
+
+var countersPruneCmd = &cobra.Command{
+	Use:          "prune",
+	SilenceUsage: true,
+	Short:        "Archive analog counters for old dates",
+	Long: `Move analog counters for dates older than --older-than (or the
+counter_retention setting) from the counter file to an archive next to it,
+e.g. counters.archive.json. Archived dates keep their numbers: backdated
+notes continue from the archived value.`,
+	Example: `  stamp counters prune --older-than 90d
+  stamp counters prune --older-than 1y --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		age := cfg.CounterRetention
+		if cmd.Flags().Changed("older-than") {
+			age = flagPruneOlderThan
+		}
+		if age == "" {
+			return fmt.Errorf("--older-than is required when counter_retention is not set")
+		}
+		days, err := config.ParseRetention(age)
+		if err != nil {
+			return err
+		}
+
+		cutoff := counter.Cutoff(time.Now(), days)
+		moved, err := cntr.Prune(cutoff, flagPruneDryRun)
+		if err != nil {
+			return err
+		}
+
+		archive := counter.ArchivePath(cntr.Path())
+		if flagFormat == formatJSON {
+			data, err := json.Marshal(map[string]any{
+				"archived": moved,
+				"before":   cutoff,
+				"archive":  archive,
+				"dry_run":  flagPruneDryRun,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		switch {
+		case flagPruneDryRun:
+			fmt.Printf("Would archive %d analog counter(s) before %s\n", moved, cutoff)
+		case !flagQuiet:
+			fmt.Printf("Archived %d analog counter(s) before %s to %s\n", moved, cutoff, archive)
+		}
+		return nil
+	},
+}
+
+func init() {
+	countersPruneCmd.Flags().StringVar(&flagPruneOlderThan, "older-than", "", "Age of the counters to archive, e.g. 90d, 12w or 1y")
+	countersPruneCmd.Flags().BoolVar(&flagPruneDryRun, "dry-run", false, "Report how many counters would be archived without changing anything")
+	countersCmd.AddCommand(countersPruneCmd)
+}
+
+// openCounters opens the configured counter backend. A backend that is
+// invalid or unavailable in this build falls back to the JSON file, so that
+// the configuration can still be fixed with stamp itself.
+func openCounters() (*counter.Manager, error) {
+	name, err := config.ParseCounterBackend(cfg.CounterBackend)
+	backend := counter.Backend(name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Config warning: counter_backend: %v\n", err)
+		return counter.New(cfg.CounterFile)
+	}
+
+	manager, err := counter.Open(backend, counterPath(backend))
+	if err != nil && backend != counter.BackendJSON {
+		fmt.Fprintf(os.Stderr, "Config warning: counter_backend: %v; using %s\n", err, cfg.CounterFile)
+		return counter.New(cfg.CounterFile)
+	}
+	return manager, err
+}
+
+// counterPath returns where backend keeps the counters: SQLite uses a .db
+// file next to a counter_file ending in .json, such as the default
+// counters.json.
+func counterPath(backend counter.Backend) string {
+	path := cfg.CounterFile
+	if ext := filepath.Ext(path); backend == counter.BackendSQLite && strings.EqualFold(ext, ".json") {
+		path = strings.TrimSuffix(path, ext) + ".db"
+	}
+	return path
+}
@@ -0,0 +1,28 @@
+//go:build sqlite && cgo
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenCountersSQLiteAfterConfigSet(t *testing.T) {
+	home := setupCLI(t)
+	if err := runCLI(t, "config", "set", "counter_backend", "sqlite"); err != nil {
+		t.Fatalf("config set error = %v", err)
+	}
+	loadCLI(t)
+
+	database := filepath.Join(home, ".stamp", "counters.db")
+	if cntr.Path() != database {
+		t.Fatalf("counters opened at %q, want %q", cntr.Path(), database)
+	}
+	if _, err := cntr.NextAnalogAfter("2025-11-12", 0); err != nil {
+		t.Fatalf("NextAnalogAfter() error = %v", err)
+	}
+	if _, err := os.Stat(database); err != nil {
+		t.Errorf("database not created: %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/toto/stamp/internal/config"
+	"github.com/toto/stamp/internal/counter"
+)
+
+func TestCounterPathAfterConfigSet(t *testing.T) {
+	home := setupCLI(t)
+	if err := runCLI(t, "config", "set", "counter_backend", "sqlite"); err != nil {
+		t.Fatalf("config set error = %v", err)
+	}
+	loadCLI(t)
+
+	name, err := config.ParseCounterBackend(cfg.CounterBackend)
+	backend := counter.Backend(name)
+	if err != nil || backend != counter.BackendSQLite {
+		t.Fatalf("counter_backend = %q, %v", cfg.CounterBackend, err)
+	}
+	if got, want := counterPath(backend), filepath.Join(home, ".stamp", "counters.db"); got != want {
+		t.Errorf("counterPath() = %q, want %q", got, want)
+	}
+
+	cfg.CounterFile = filepath.Join(home, "shared.json")
+	if got, want := counterPath(backend), filepath.Join(home, "shared.db"); got != want {
+		t.Errorf("counterPath() = %q, want %q", got, want)
+	}
+	cfg.CounterFile = filepath.Join(home, "team.sqlite")
+	if got := counterPath(backend); got != cfg.CounterFile {
+		t.Errorf("counterPath() = %q, want counter_file kept", got)
+	}
+	if got := counterPath(counter.BackendJSON); got != cfg.CounterFile {
+		t.Errorf("counterPath(json) = %q, want counter_file", got)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/counter"
+	"github.com/toto/stamp/internal/generator"
+)
+
+var (
+	flagLogSince string
+	flagLogUntil string
+	flagLogType  string
+)
+
+var logCmd = &cobra.Command{
+	Use:          "log",
+	SilenceUsage: true,
+	Short:        "Show the history of issued IDs and counter resets",
+	Long: `Show the history of issued IDs, oldest first.
+
+Analog numbers, counter resets and sequential IDs claimed with --reserve or
+stamp new are appended to a log next to the counter file, together with the
+working directory and host they were issued on. After an accidental
+--reset, the last analog number issued for a day can be found here.
+
+--since and --until take the same expressions as --date and select whole
+days, both inclusive.`,
+	Example: `  stamp log --since yesterday
+  stamp log --type analog --since 2025-11-01 --until 2025-11-30
+  stamp log --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter := counter.Filter{Type: flagLogType}
+		if flagLogSince != "" {
+			day, err := logDay(flagLogSince)
+			if err != nil {
+				return fmt.Errorf("--since: %w", err)
+			}
+			filter.Since = day
+		}
+		if flagLogUntil != "" {
+			day, err := logDay(flagLogUntil)
+			if err != nil {
+				return fmt.Errorf("--until: %w", err)
+			}
+			filter.Until = day.AddDate(0, 0, 1)
+		}
+
+		events, err := cntr.History(filter)
+		if err != nil {
+			return err
+		}
+
+		switch flagFormat {
+		case formatJSON:
+			if events == nil {
+				events = []counter.Event{}
+			}
+			data, err := json.Marshal(events)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		case formatEnv:
+			return fmt.Errorf("log does not support --format env")
+		}
+
+		loc := gen.Now().Location()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, event := range events {
+			what := event.ID
+			if event.Reset {
+				what = fmt.Sprintf("reset %s (was %d)", cmp.Or(event.Date, event.ID), event.Previous)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+				event.Time.In(loc).Format("2006-01-02 15:04:05"), event.Type, what, event.Host, event.Dir)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	logCmd.Flags().StringVar(&flagLogSince, "since", "", "Only show events on or after this day")
+	logCmd.Flags().StringVar(&flagLogUntil, "until", "", "Only show events on or before this day")
+	logCmd.Flags().StringVar(&flagLogType, "type", "", "Only show events for this note type")
+}
+
+// logDay resolves a date expression to the start of its day.
+func logDay(expr string) (time.Time, error) {
+	t, err := generator.ParseDate(expr, gen.Now())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()), nil
+}
+
+// recordIssued adds an ID handed out outside the counter file to the
+// history. Failing to do so does not fail the command.
+func recordIssued(typ, id string) {
+	if err := cntr.Record(typ, id); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not update counter history: %v\n", err)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/calendar"
 	"github.com/toto/stamp/internal/clipboard"
 	"github.com/toto/stamp/internal/config"
 	"github.com/toto/stamp/internal/counter"
 	"github.com/toto/stamp/internal/generator"
+	"github.com/toto/stamp/internal/naming"
 	"github.com/toto/stamp/internal/obsidian"
 	"github.com/toto/stamp/internal/sequential"
 )
@@ -23,22 +25,41 @@ var (
 var (
 	cfg  *config.Config
 	cntr *counter.Manager
-	gen  *generator.Generator
+	// counterErr is why cntr could not be opened. Commands other than
+	// stamp config fail with it, so that a broken setup can still be fixed.
+	counterErr error
+	// counters issues analog and shared sequential numbers: cntr, or remote
+	// when counter_server is configured.
+	counters counter.Service
+	remote   *counter.Client
+	gen      *generator.Generator
+	vault    string // Obsidian vault root, empty outside a vault
+
+	vaultLayouts obsidian.Layouts                 // formats detected in the vault
+	vaultNotes   map[string]obsidian.NoteSettings // folders and templates by note type
+	titlePolicy  naming.TitlePolicy
 
 	// Flags
 	flagExt            bool
 	flagCopy           bool
 	flagQuiet          bool
+	flagDate           string
+	flagFormat         string
+	flagVaultPath      bool
 	flagAnalogCheck    bool
 	flagAnalogReset    bool
 	flagAnalogCounter  bool
 	flagProjectCheck   bool
 	flagProjectCounter bool
+	flagProjectReserve reserveFlags
+	flagProjectReset   bool
 	flagSeqPrefix      string
 	flagSeqWidth       int
 	flagSeqStart       int
 	flagSeqCheck       bool
 	flagSeqCounter     bool
+	flagSeqReserve     reserveFlags
+	flagSeqReset       bool
 )
 
 var rootCmd = &cobra.Command{
@@ -51,29 +72,48 @@ Available note types:
   - fleeting: YYYY-MM-DD-FHHMMSS format
   - voice:    YYYY-MM-DD-VTHHMMSS format
   - analog:   YYYY-MM-DD-AN format (sequential per day)
+  - weekly:   YYYY-Www format (ISO 8601 week by default)
   - monthly:  YYYY-MM format
+  - quarterly: YYYY-QN format
   - yearly:   YYYY format
   - project:  PXXXX format (shorthand for seq --prefix P --width 4)
   - seq:      Custom prefix + zero-padded number (workspace scan)
+  - zettel:   Luhmann-style Folgezettel IDs (1, 1a, 1a1, 21/3a7b)
+
+Use "stamp new <type> [title]" to create the note file from a template.
 
 Default (no type): YYYY-MM-DD-HHMM format`,
-	RunE: runDefault,
+	PersistentPreRunE: preRun,
+	RunE:              runDefault,
 }
 
 func init() {
-	rootCmd.PersistentFlags().BoolVar(&flagExt, "ext", false, "Add .md extension to output")
-	rootCmd.PersistentFlags().BoolVar(&flagCopy, "copy", false, "Copy to clipboard (macOS only)")
+	rootCmd.PersistentFlags().BoolVar(&flagExt, "ext", false, "Add file extension to output (.md unless the note type overrides it)")
+	rootCmd.PersistentFlags().BoolVar(&flagCopy, "copy", false, "Copy to clipboard")
 	rootCmd.PersistentFlags().BoolVarP(&flagQuiet, "quiet", "q", false, "Quiet mode (no additional output)")
+	rootCmd.PersistentFlags().StringVar(&flagDate, "date", "", "Generate for another date/time (e.g. 2025-11-10, yesterday, -3d, \"last monday 14:00\")")
+	rootCmd.PersistentFlags().StringVar(&flagDate, "at", "", "Alias for --date")
+	rootCmd.PersistentFlags().StringVar(&flagFormat, "format", formatText, "Output format: text, json or env")
+	rootCmd.PersistentFlags().BoolVar(&flagVaultPath, "vault-path", false, "Print the vault-relative path of the note, in the folder the Obsidian vault keeps it in")
 
 	// Add subcommands
 	rootCmd.AddCommand(dailyCmd)
 	rootCmd.AddCommand(fleetingCmd)
 	rootCmd.AddCommand(voiceCmd)
 	rootCmd.AddCommand(analogCmd)
+	rootCmd.AddCommand(weeklyCmd)
 	rootCmd.AddCommand(monthlyCmd)
+	rootCmd.AddCommand(quarterlyCmd)
 	rootCmd.AddCommand(yearlyCmd)
 	rootCmd.AddCommand(seqCmd)
 	rootCmd.AddCommand(projectCmd)
+	rootCmd.AddCommand(zettelCmd)
+	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(countersCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(parseCmd)
+	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
@@ -81,7 +121,7 @@ var dailyCmd = &cobra.Command{
 	Use:   "daily",
 	Short: "Generate daily note filename (YYYY-MM-DD)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return outputResult(gen.Daily())
+		return outputResult(noteResult{Type: "daily", ID: gen.Daily()})
 	},
 }
 
@@ -89,7 +129,7 @@ var fleetingCmd = &cobra.Command{
 	Use:   "fleeting",
 	Short: "Generate fleeting note filename (YYYY-MM-DD-FHHMMSS)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return outputResult(gen.Fleeting())
+		return outputResult(noteResult{Type: "fleeting", ID: gen.Fleeting()})
 	},
 }
 
@@ -97,46 +137,80 @@ var voiceCmd = &cobra.Command{
 	Use:   "voice",
 	Short: "Generate voice transcript filename (YYYY-MM-DD-VTHHMMSS)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return outputResult(gen.Voice())
+		return outputResult(noteResult{Type: "voice", ID: gen.Voice()})
 	},
 }
 
 var analogCmd = &cobra.Command{
 	Use:   "analog",
 	Short: "Generate analog/slipbox note filename (YYYY-MM-DD-AN)",
+	Long: `Generate analog/slipbox note filenames numbered per day (YYYY-MM-DD-AN).
+
+--mode (or analog_counter in the config) selects where numbers come from:
+  file    the counter file (default)
+  scan    the highest YYYY-MM-DD-AN already in the current directory
+  hybrid  the larger of the two, recorded in the counter file
+
+Scan and hybrid modes follow the scan flags and the "analog" scan settings.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		mode, err := analogMode(cmd)
+		if err != nil {
+			return err
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		date := gen.GetCurrentDate()
+
 		if flagAnalogCheck {
-			result, err := cntr.CheckAnalog(gen.GetCurrentDate())
+			count, err := analogCounter(cmd, mode, wd, date)
 			if err != nil {
 				return err
 			}
-			return outputResult(result)
+			return outputResult(noteResult{Type: "analog", Action: actionCheck, ID: counter.FormatAnalog(date, count+1)})
 		}
 
 		if flagAnalogReset {
-			if err := cntr.ResetAnalog(gen.GetCurrentDate()); err != nil {
+			if mode == counter.ModeScan {
+				return fmt.Errorf("--reset has no effect in scan mode; numbers come from existing notes")
+			}
+			if err := counters.ResetAnalog(date); err != nil {
 				return err
 			}
+			reset := noteResult{Type: "analog", Action: actionReset, Counter: intPtr(0)}
 			if !flagQuiet {
-				fmt.Println("Counter reset for analog notes")
+				reset.Text = "Counter reset for analog notes"
 			}
-			return nil
+			return outputResult(reset)
 		}
 
 		if flagAnalogCounter {
-			count, err := cntr.GetAnalogCounter(gen.GetCurrentDate())
+			count, err := analogCounter(cmd, mode, wd, date)
 			if err != nil {
 				return err
 			}
-			fmt.Printf("Current analog counter for %s: %d\n", gen.GetCurrentDate(), count)
-			return nil
+			return outputResult(noteResult{
+				Type:    "analog",
+				Action:  actionCounter,
+				Counter: intPtr(count),
+				Text:    fmt.Sprintf("Current analog counter for %s: %d", date, count),
+			})
 		}
 
-		result, err := cntr.NextAnalog(gen.GetCurrentDate())
+		n, err := nextAnalog(cmd, wd, date)
 		if err != nil {
 			return err
 		}
-		return outputResult(result)
+		return outputResult(noteResult{Type: "analog", ID: counter.FormatAnalog(date, n), Counter: intPtr(n)})
+	},
+}
+
+var weeklyCmd = &cobra.Command{
+	Use:   "weekly",
+	Short: "Generate weekly review filename (YYYY-Www)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return outputResult(noteResult{Type: "weekly", ID: gen.Weekly()})
 	},
 }
 
@@ -144,7 +218,15 @@ var monthlyCmd = &cobra.Command{
 	Use:   "monthly",
 	Short: "Generate monthly review filename (YYYY-MM)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return outputResult(gen.Monthly())
+		return outputResult(noteResult{Type: "monthly", ID: gen.Monthly()})
+	},
+}
+
+var quarterlyCmd = &cobra.Command{
+	Use:   "quarterly",
+	Short: "Generate quarterly review filename (YYYY-QN)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return outputResult(noteResult{Type: "quarterly", ID: gen.Quarterly()})
 	},
 }
 
@@ -152,7 +234,7 @@ var yearlyCmd = &cobra.Command{
 	Use:   "yearly",
 	Short: "Generate yearly review filename (YYYY)",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return outputResult(gen.Yearly())
+		return outputResult(noteResult{Type: "yearly", ID: gen.Yearly()})
 	},
 }
 
@@ -162,10 +244,13 @@ var projectCmd = &cobra.Command{
 	Long:  "Equivalent to `stamp seq --prefix P --width 4`.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runSeqCommand(seqCommandOptions{
-			Spec:         sequential.Spec{Prefix: "P", Width: 4, Start: 1},
+			Spec:         sequential.Spec{Prefix: "P", Width: 4, Start: 1, Scan: scanOptions(cmd, "P")},
+			Type:         "project",
 			CounterLabel: "project",
 			Check:        flagProjectCheck,
 			Counter:      flagProjectCounter,
+			Reserve:      flagProjectReserve.mode(),
+			Reset:        flagProjectReset,
 			TitleArgs:    args,
 		})
 	},
@@ -177,13 +262,12 @@ var seqCmd = &cobra.Command{
 	Short:   "Generate sequential codes from the current directory",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runSeqCommand(seqCommandOptions{
-			Spec: sequential.Spec{
-				Prefix: flagSeqPrefix,
-				Width:  flagSeqWidth,
-				Start:  flagSeqStart,
-			},
+			Type:      "seq",
+			Spec:      seqSpec(cmd),
 			Check:     flagSeqCheck,
 			Counter:   flagSeqCounter,
+			Reserve:   flagSeqReserve.mode(),
+			Reset:     flagSeqReset,
 			TitleArgs: args,
 		})
 	},
@@ -192,8 +276,12 @@ var seqCmd = &cobra.Command{
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("stamp version %s\ncommit: %s\nbuilt: %s\n", version, commit, date)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return outputResult(noteResult{
+			Type:   "version",
+			Action: actionVersion,
+			Text:   fmt.Sprintf("stamp version %s\ncommit: %s\nbuilt: %s", version, commit, date),
+		})
 	},
 }
 
@@ -202,15 +290,60 @@ func init() {
 	analogCmd.Flags().BoolVar(&flagAnalogCheck, "check", false, "Check next number without incrementing")
 	analogCmd.Flags().BoolVar(&flagAnalogReset, "reset", false, "Reset counter")
 	analogCmd.Flags().BoolVar(&flagAnalogCounter, "counter", false, "Show current counter value")
+	analogCmd.Flags().StringVar(&flagAnalogMode, "mode", "", "Counter source: file, scan or hybrid (default from config)")
+	addScanFlags(analogCmd)
 
 	projectCmd.Flags().BoolVar(&flagProjectCheck, "check", false, "Check next number without incrementing")
 	projectCmd.Flags().BoolVar(&flagProjectCounter, "counter", false, "Show highest existing number")
+	addReserveFlags(projectCmd, &flagProjectReserve)
+	addSharedResetFlag(projectCmd, &flagProjectReset)
+	addScanFlags(projectCmd)
 
 	seqCmd.Flags().StringVar(&flagSeqPrefix, "prefix", "P", "Prefix for generated code (case-insensitive match)")
 	seqCmd.Flags().IntVar(&flagSeqWidth, "width", 4, "Number of digits for zero padding")
 	seqCmd.Flags().IntVar(&flagSeqStart, "start", 1, "Starting number when no entries are found")
 	seqCmd.Flags().BoolVar(&flagSeqCheck, "check", false, "Check next number without creating files")
 	seqCmd.Flags().BoolVar(&flagSeqCounter, "counter", false, "Show highest existing number for the prefix")
+	addReserveFlags(seqCmd, &flagSeqReserve)
+	addSharedResetFlag(seqCmd, &flagSeqReset)
+	addScanFlags(seqCmd)
+}
+
+// applyDateFlag pins the generator clock when --date/--at is provided so that
+// every note type, including the per-date analog counter, uses that moment.
+func preRun(cmd *cobra.Command, args []string) error {
+	if counterErr != nil && !isConfigCommand(cmd) {
+		return fmt.Errorf("initializing counter: %w", counterErr)
+	}
+	return applyDateFlag(cmd, args)
+}
+
+// isConfigCommand reports whether cmd is stamp config or one of its
+// subcommands.
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if c.Name() == "config" && !c.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
+
+func applyDateFlag(cmd *cobra.Command, args []string) error {
+	if err := validateFormat(flagFormat); err != nil {
+		return err
+	}
+
+	if flagDate == "" {
+		return nil
+	}
+
+	at, err := generator.ParseDate(flagDate, gen.Now())
+	if err != nil {
+		return err
+	}
+	gen.SetTime(at)
+	return nil
 }
 
 func runDefault(cmd *cobra.Command, args []string) error {
@@ -226,36 +359,18 @@ func runDefault(cmd *cobra.Command, args []string) error {
 	}
 
 	// Default behavior: output timestamp
-	return outputResult(gen.Default())
-}
-
-func outputResult(result string) error {
-	if flagExt {
-		result += ".md"
-	}
-
-	if flagCopy {
-		if err := clipboard.Copy(result); err != nil {
-			// Fall back to stdout if clipboard fails
-			fmt.Println(result)
-			return fmt.Errorf("clipboard error: %w", err)
-		}
-		if !flagQuiet {
-			fmt.Println(result)
-			fmt.Println("Copied to clipboard!")
-		}
-	} else {
-		fmt.Println(result)
-	}
-
-	return nil
+	return outputResult(noteResult{Type: "default", ID: gen.Default()})
 }
 
 type seqCommandOptions struct {
+	Type         string
 	Spec         sequential.Spec
 	CounterLabel string
+	Extension    string
 	Check        bool
 	Counter      bool
+	Reserve      string // "", "file" or "dir"
+	Reset        bool   // reset the counter server's counter
 	TitleArgs    []string
 }
 
@@ -265,11 +380,33 @@ func runSeqCommand(opts seqCommandOptions) error {
 		return err
 	}
 
+	if opts.Reset {
+		if remote == nil {
+			return fmt.Errorf("--reset needs counter_server; local sequential numbers come from existing entries")
+		}
+		seq := sharedSeq(opts.Type, opts.Spec)
+		if err := remote.ResetSeq(seq); err != nil {
+			return err
+		}
+		reset := noteResult{Type: opts.Type, Action: actionReset, Counter: intPtr(0)}
+		if !flagQuiet {
+			reset.Text = fmt.Sprintf("Shared counter reset for prefix %s", strings.ToUpper(seq.Prefix))
+		}
+		return outputResult(reset)
+	}
+
 	if opts.Counter {
 		highest, err := sequential.Highest(wd, opts.Spec)
 		if err != nil {
 			return err
 		}
+		if remote != nil {
+			shared, err := remote.GetSeq(sharedSeq(opts.Type, opts.Spec))
+			if err != nil {
+				return err
+			}
+			highest = max(highest, shared)
+		}
 
 		label := opts.CounterLabel
 		if label == "" {
@@ -278,28 +415,139 @@ func runSeqCommand(opts seqCommandOptions) error {
 			label = fmt.Sprintf("%s counter", label)
 		}
 
+		text := fmt.Sprintf("Current %s: %d", label, highest)
 		if highest == 0 {
-			fmt.Printf("Current %s: none\n", label)
-		} else {
-			fmt.Printf("Current %s: %d\n", label, highest)
+			text = fmt.Sprintf("Current %s: none", label)
 		}
-		return nil
+		return outputResult(noteResult{Type: opts.Type, Action: actionCounter, Counter: intPtr(highest), Text: text})
+	}
+
+	result := noteResult{Type: opts.Type, Ext: opts.Extension}
+	if !opts.Check {
+		result.Title = strings.Join(opts.TitleArgs, " ")
 	}
 
-	code, _, err := sequential.Next(wd, opts.Spec)
+	if opts.Reserve != "" && !opts.Check {
+		reservation, path, err := reserveSeqEntry(wd, opts.Spec, result, opts.Reserve)
+		if err != nil {
+			return err
+		}
+		result.ID = reservation.Code
+		result.Counter = intPtr(reservation.Value)
+		result.Action = actionCreate
+		recordIssued(opts.Type, reservation.Code)
+		result.Dir = opts.Reserve == "dir"
+		if flagFormat != formatText {
+			result.Path = path
+		}
+		return outputResult(result)
+	}
+
+	code, value, err := sequential.Next(wd, opts.Spec)
 	if err != nil {
 		return err
 	}
-
-	title := strings.Join(opts.TitleArgs, " ")
+	if remote != nil {
+		if value, err = nextShared(opts, value, opts.Check); err != nil {
+			return err
+		}
+		code = sequential.Format(opts.Spec, value)
+	}
+	result.ID = code
+	result.Counter = intPtr(value)
 	if opts.Check {
-		title = ""
+		result.Action = actionCheck
+	}
+
+	return outputResult(result)
+}
+
+// seqSpec builds the spec for `stamp seq`, taking defaults from the `seq`
+// config section for flags that were not given.
+func seqSpec(cmd *cobra.Command) sequential.Spec {
+	spec := defaultSeqSpec()
+
+	flags := cmd.Flags()
+	if flags.Changed("prefix") {
+		spec.Prefix = flagSeqPrefix
+	}
+	if flags.Changed("width") {
+		spec.Width = flagSeqWidth
 	}
-	if title != "" {
-		code += " " + title
+	if flags.Changed("start") {
+		spec.Start = flagSeqStart
 	}
+	spec.Scan = scanOptions(cmd, spec.Prefix)
+	return spec
+}
 
-	return outputResult(code)
+// defaultSeqSpec is the spec of `stamp seq` without flags: the `seq` config
+// section over prefix P, width 4 and start 1.
+func defaultSeqSpec() sequential.Spec {
+	spec := sequential.Spec{Prefix: "P", Width: 4, Start: 1}
+	if cfg.Seq.Prefix != "" {
+		spec.Prefix = cfg.Seq.Prefix
+	}
+	if cfg.Seq.Width > 0 {
+		spec.Width = cfg.Seq.Width
+	}
+	if cfg.Seq.Start > 0 {
+		spec.Start = cfg.Seq.Start
+	}
+	return spec
+}
+
+// reserveSeqEntry atomically claims the next ID in dir, from the counter
+// server when there is one, and materialises it as an empty file or a
+// directory so that concurrent runs cannot reuse it.
+func reserveSeqEntry(dir string, spec sequential.Spec, result noteResult, mode string) (*sequential.Reservation, string, error) {
+	reservation, err := sequential.ReserveFrom(dir, spec, sharedIssue(result.Type, spec))
+	if err != nil {
+		return nil, "", err
+	}
+
+	result.ID = reservation.Code
+	var path string
+	if mode == "dir" {
+		path, err = reservation.MaterializeDir(result.name())
+	} else {
+		path, err = reservation.Materialize(result.filename(), nil)
+	}
+	if err != nil {
+		reservation.Release()
+		return nil, "", err
+	}
+	return reservation, path, nil
+}
+
+// reserveFlags holds --reserve and --reserve-dir.
+type reserveFlags struct {
+	file, dir bool
+}
+
+// mode returns "file", "dir" or "" when neither flag is set.
+func (r reserveFlags) mode() string {
+	switch {
+	case r.dir:
+		return "dir"
+	case r.file:
+		return "file"
+	}
+	return ""
+}
+
+// addReserveFlags registers --reserve and --reserve-dir, which claim the ID by
+// creating the entry as an empty file or a directory.
+func addReserveFlags(cmd *cobra.Command, target *reserveFlags) {
+	cmd.Flags().BoolVar(&target.file, "reserve", false, "Atomically claim the ID by creating an empty file")
+	cmd.Flags().BoolVar(&target.dir, "reserve-dir", false, "Atomically claim the ID by creating a directory")
+	cmd.MarkFlagsMutuallyExclusive("reserve", "reserve-dir")
+}
+
+// addSharedResetFlag registers --reset, which resets the counter server's
+// counter for the prefix.
+func addSharedResetFlag(cmd *cobra.Command, target *bool) {
+	cmd.Flags().BoolVar(target, "reset", false, "Reset the shared counter on the counter server (needs counter_server)")
 }
 
 func normalizePrefix(spec sequential.Spec) string {
@@ -309,53 +557,117 @@ func normalizePrefix(spec sequential.Spec) string {
 	return spec.Prefix
 }
 
+// newGenerator builds the generator for the configured timezone and week
+// start. Invalid values fall back to the defaults with a warning rather than
+// stopping stamp, so that stamp config can still report and fix them.
+func newGenerator() *generator.Generator {
+	g, err := generator.New(cfg.Timezone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Config warning: timezone: %v; using the local time zone\n", err)
+		g, _ = generator.New("")
+	}
+	if cfg.WeekStart != "" {
+		if weekStart, err := calendar.ParseWeekday(cfg.WeekStart); err != nil {
+			fmt.Fprintf(os.Stderr, "Config warning: week_start: %v; using Monday\n", err)
+		} else {
+			g.SetWeekStart(weekStart)
+		}
+	}
+	return g
+}
+
+func applyObsidianLayouts(layouts obsidian.Layouts) {
+	vaultLayouts = layouts
+	gen.ApplyLayouts(generator.LayoutOverrides{
+		Default:   layouts.Default,
+		Daily:     layouts.Daily,
+		Weekly:    layouts.Weekly,
+		Monthly:   layouts.Monthly,
+		Quarterly: layouts.Quarterly,
+		Yearly:    layouts.Yearly,
+	})
+}
+
 func main() {
 	var err error
 
 	// Load configuration
 	cfg, err = config.Load()
 	if err != nil {
-		// Use defaults if config loading fails
+		// Use defaults if config loading fails, but say so
+		fmt.Fprintf(os.Stderr, "Config error: %v\nUsing default configuration; run `stamp config validate` for details.\n", err)
 		cfg = config.Default()
 	}
+	for _, problem := range cfg.Ignored() {
+		fmt.Fprintf(os.Stderr, "Config warning: %s\n", problem)
+	}
 
-	// Initialize counter manager
-	cntr, err = counter.New(cfg.CounterFile)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error initializing counter: %v\n", err)
-		os.Exit(1)
+	// Initialize counter manager; preRun reports a failure to the commands
+	// that need it.
+	cntr, counterErr = openCounters()
+	if counterErr == nil {
+		counters = cntr
+		if cfg.CounterServer.URL != "" {
+			remote = counter.NewClient(cfg.CounterServer.URL, cfg.CounterServer.Token, cntr)
+			counters = remote
+		}
 	}
 
-	// Initialize generator
-	gen, err = generator.New(cfg.Timezone)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error initializing generator: %v\n", err)
-		os.Exit(1)
+	gen = newGenerator()
+
+	titlePolicy = naming.TitlePolicy(cfg.Title)
+	if err := titlePolicy.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Config warning: title: %v\n", err)
+		titlePolicy = naming.TitlePolicy{}
+	}
+
+	if cfg.CounterRetention != "" && cntr != nil {
+		if days, err := config.ParseRetention(cfg.CounterRetention); err != nil {
+			fmt.Fprintf(os.Stderr, "Config warning: counter_retention: %v\n", err)
+		} else {
+			cntr.SetRetention(days)
+		}
+	}
+
+	if err := clipboard.SetProvider(cfg.Clipboard); err != nil {
+		fmt.Fprintf(os.Stderr, "Config warning: clipboard: %v\n", err)
 	}
 
 	if wd, err := os.Getwd(); err == nil {
-		if detectResult, detectErr := obsidian.Detect(wd); detectErr != nil {
+		detectResult, detectErr := obsidian.DetectWith(wd, obsidian.Options{Strict: cfg.Obsidian.Strict})
+		if detectResult != nil {
+			for _, diag := range detectResult.Diagnostics {
+				if diag.Warning {
+					fmt.Fprintf(os.Stderr, "Obsidian format warning: %s\n", diag)
+					continue
+				}
+				fmt.Fprintf(os.Stderr, "Obsidian format warning: %s; using the built-in %s format\n", diag, diag.Note)
+			}
+		}
+		if detectErr != nil {
 			fmt.Fprintf(os.Stderr, "Obsidian detection warning: %v\n", detectErr)
 			if detectResult != nil && detectResult.InVault {
-				gen.ApplyLayouts(generator.LayoutOverrides{
-					Default: detectResult.Layouts.Default,
-					Daily:   detectResult.Layouts.Daily,
-				})
+				vault, vaultNotes = detectResult.VaultPath, detectResult.Notes
+				applyObsidianLayouts(detectResult.Layouts)
 			}
 		} else if detectResult.InVault {
-			gen.ApplyLayouts(generator.LayoutOverrides{
-				Default: detectResult.Layouts.Default,
-				Daily:   detectResult.Layouts.Daily,
-			})
+			vault, vaultNotes = detectResult.VaultPath, detectResult.Notes
+			applyObsidianLayouts(detectResult.Layouts)
 		}
 	}
 
+	registerCustomTypes(cfg.Types)
+
 	// Apply default extension flag from config
 	if cfg.AlwaysExtension && !rootCmd.PersistentFlags().Changed("ext") {
 		flagExt = true
 	}
 
-	if err := rootCmd.Execute(); err != nil {
+	err = rootCmd.Execute()
+	if cntr != nil {
+		cntr.Close()
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
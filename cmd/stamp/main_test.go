@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/toto/stamp/internal/config"
+	"github.com/toto/stamp/internal/generator"
+)
+
+// setupCLI points stamp at an empty home directory, which is also the
+// working directory, and sets up the globals as main does. It returns the
+// home directory.
+func setupCLI(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, env := range []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME", config.EnvConfig, config.EnvCounterFile, config.EnvTimezone, config.EnvWeekStart} {
+		t.Setenv(env, "")
+	}
+	t.Chdir(home)
+	loadCLI(t)
+	return home
+}
+
+// loadCLI loads the configuration and opens the counters as main does.
+func loadCLI(t *testing.T) {
+	t.Helper()
+	var err error
+	if cfg, err = config.Load(); err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if cntr, err = openCounters(); err != nil {
+		t.Fatalf("openCounters() error = %v", err)
+	}
+	t.Cleanup(func() { cntr.Close() })
+	counters, remote, counterErr = cntr, nil, nil
+	if gen, err = generator.New("UTC"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runCLI runs stamp with args, then resets the flags it set.
+func runCLI(t *testing.T, args ...string) error {
+	t.Helper()
+	rootCmd.SetArgs(args)
+	err := rootCmd.Execute()
+	resetFlags(rootCmd)
+	return err
+}
+
+// captureCLI runs stamp with args and returns what it printed on stdout.
+func captureCLI(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := runCLI(t, args...)
+	os.Stdout = stdout
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out), runErr
+}
+
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+func TestReserveFlags(t *testing.T) {
+	home := setupCLI(t)
+
+	if err := runCLI(t, "project", "--reserve-dir", "New", "Tool"); err != nil {
+		t.Fatalf("project --reserve-dir error = %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(home, "P0001 New Tool")); err != nil || !info.IsDir() {
+		t.Errorf("--reserve-dir did not create the folder: %v", err)
+	}
+
+	// A title that happens to be "dir" is a title.
+	if err := runCLI(t, "project", "--reserve", "dir"); err != nil {
+		t.Fatalf("project --reserve error = %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(home, "P0002 dir.md")); err != nil || info.IsDir() {
+		t.Errorf("--reserve did not create the file: %v", err)
+	}
+
+	if err := runCLI(t, "seq", "--reserve", "--reserve-dir"); err == nil {
+		t.Error("--reserve with --reserve-dir error = nil")
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/config"
+	"github.com/toto/stamp/internal/counter"
+	"github.com/toto/stamp/internal/generator"
+	"github.com/toto/stamp/internal/obsidian"
+	"github.com/toto/stamp/internal/sequential"
+)
+
+var (
+	flagNewDir      string
+	flagNewTemplate string
+	flagNewVault    bool
+)
+
+// defaultNoteTemplate is rendered when no template is configured for a type.
+const defaultNoteTemplate = `---
+id: {{.ID}}
+{{- if .Title}}
+title: {{.Title}}
+{{- end}}
+type: {{.Type}}
+created: {{.Date.Format "2006-01-02T15:04:05Z07:00"}}
+---
+
+# {{if .Title}}{{.Title}}{{else}}{{.ID}}{{end}}
+`
+
+var newCmd = &cobra.Command{
+	Use:   "new <type> [title]",
+	Short: "Create a note file from a template",
+	Long: `Create a note file named after the generated ID and print its path.
+
+The body is rendered with Go's text/template. Available fields:
+  {{.ID}}        generated note ID
+  {{.Title}}     title from the arguments (may be empty)
+  {{.Type}}      note type name
+  {{.Date}}      creation time (time.Time, e.g. {{.Date.Format "2006-01-02"}})
+  {{.Filename}}  file name including extension
+  {{.Path}}      full path of the created file
+  {{.Vault}}     Obsidian vault root, empty outside a vault
+
+Templates are chosen with --template or the "templates" map in config.yaml.
+Existing files are never overwritten.
+
+With --vault the note goes to the folder the Obsidian vault's Daily Notes or
+Periodic Notes settings name for its type, and the vault's template note is
+used unless --template is given. Vault templates use the plugins' variables
+({{title}}, {{date}}, {{date:YYYY}}, {{time}}, {{yesterday}}, {{monday:D}},
+...) instead of Go templates.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNew(cmd, args[0], strings.Join(args[1:], " "))
+	},
+}
+
+func init() {
+	newCmd.Flags().StringVar(&flagNewDir, "dir", "", "Directory to create the note in (default: current directory)")
+	newCmd.Flags().StringVar(&flagNewTemplate, "template", "", "Template file to render (overrides config)")
+	newCmd.Flags().BoolVar(&flagNewVault, "vault", false, "Create the note in the Obsidian vault's folder for its type, from the vault's template")
+	newCmd.MarkFlagsMutuallyExclusive("dir", "vault")
+	addScanFlags(newCmd)
+}
+
+// noteData is passed to note templates.
+type noteData struct {
+	ID       string
+	Title    string
+	Type     string
+	Date     time.Time
+	Filename string
+	Path     string
+	Vault    string
+}
+
+// noteSpec describes how `stamp new` produces IDs for a note type.
+type noteSpec struct {
+	ext string
+	// titled types append the title to the filename, as project and seq do.
+	titled bool
+	next   func(dir string) (string, error)
+	// seq is set for sequential types, whose IDs are reserved atomically.
+	seq *sequential.Spec
+}
+
+func runNew(cmd *cobra.Command, typeName, title string) error {
+	dir := flagNewDir
+	if flagNewVault {
+		if vault == "" {
+			return fmt.Errorf("--vault: not inside an Obsidian vault")
+		}
+		var err error
+		if dir, err = vaultFile(vaultNote(typeName).Path("")); err != nil {
+			return fmt.Errorf("--vault: folder: %w", err)
+		}
+	}
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		dir = wd
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	spec, err := lookupNoteSpec(cmd, typeName)
+	if err != nil {
+		return err
+	}
+
+	render, err := loadNoteTemplate(typeName)
+	if err != nil {
+		return err
+	}
+
+	var reservation *sequential.Reservation
+	var id string
+	if spec.seq != nil {
+		seqSpec := *spec.seq
+		seqSpec.Scan = scanOptions(cmd, seqSpec.Prefix)
+		reservation, err = sequential.ReserveFrom(dir, seqSpec, sharedIssue(typeName, seqSpec))
+		if err != nil {
+			return err
+		}
+		id = reservation.Code
+		recordIssued(typeName, id)
+	} else {
+		id, err = spec.next(dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	name := id
+	if spec.titled {
+		name = titledName(id, title)
+	}
+	filename := name + spec.ext
+	path := filepath.Join(dir, filename)
+
+	body, err := render(noteData{
+		ID:       id,
+		Title:    title,
+		Type:     typeName,
+		Date:     gen.Now(),
+		Filename: filename,
+		Path:     path,
+		Vault:    vault,
+	})
+	if err != nil {
+		if reservation != nil {
+			reservation.Release()
+		}
+		return fmt.Errorf("render template: %w", err)
+	}
+
+	if reservation != nil {
+		if _, err := reservation.Materialize(filename, body); err != nil {
+			reservation.Release()
+			return err
+		}
+	} else if err := writeNewFile(path, body); err != nil {
+		return err
+	}
+
+	return outputResult(noteResult{
+		Type:   typeName,
+		Action: actionCreate,
+		ID:     id,
+		Title:  title,
+		Name:   name,
+		Ext:    spec.ext,
+		Path:   path,
+	})
+}
+
+// writeNewFile creates path exclusively so that existing notes are never
+// overwritten, even when two invocations race.
+func writeNewFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("refusing to overwrite existing file %s", path)
+		}
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadNoteTemplate returns the renderer of the note body for typeName: the
+// --template file, the vault's template note with --vault, the configured
+// template or the default one.
+func loadNoteTemplate(typeName string) (func(noteData) ([]byte, error), error) {
+	path := flagNewTemplate
+	if path == "" && flagNewVault {
+		if settings := vaultNote(typeName); settings.TemplatePath() != "" {
+			return loadVaultTemplate(settings)
+		}
+	}
+	if path == "" {
+		path = cfg.Templates[typeName]
+	}
+	if path == "" {
+		return goTemplate(template.New("note").Parse(defaultNoteTemplate))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(path)).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s: %w", path, err)
+	}
+	return goTemplate(tmpl, nil)
+}
+
+func goTemplate(tmpl *template.Template, err error) (func(noteData) ([]byte, error), error) {
+	if err != nil {
+		return nil, err
+	}
+	return func(data noteData) ([]byte, error) {
+		var body bytes.Buffer
+		err := tmpl.Execute(&body, data)
+		return body.Bytes(), err
+	}, nil
+}
+
+// loadVaultTemplate reads a template note of the vault, which uses the
+// variables of Obsidian's Daily Notes and Periodic Notes plugins.
+func loadVaultTemplate(settings obsidian.NoteSettings) (func(noteData) ([]byte, error), error) {
+	path, err := vaultFile(settings.TemplatePath())
+	if err != nil {
+		return nil, fmt.Errorf("vault template: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read vault template: %w", err)
+	}
+	return func(note noteData) ([]byte, error) {
+		return []byte(obsidian.ExpandTemplate(string(data), obsidian.TemplateData{
+			Title:  strings.TrimSuffix(note.Filename, filepath.Ext(note.Filename)),
+			Time:   note.Date,
+			Format: settings.Format,
+		})), nil
+	}, nil
+}
+
+// vaultFile resolves a slash-separated path from the vault's settings,
+// rejecting paths such as "../notes" that lead outside the vault.
+func vaultFile(rel string) (string, error) {
+	path := filepath.Join(vault, filepath.FromSlash(rel))
+	inside, err := filepath.Rel(vault, path)
+	if err != nil || inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q is outside the vault", rel)
+	}
+	return path, nil
+}
+
+// vaultNote returns the vault's settings for notes of the given type.
+func vaultNote(typeName string) obsidian.NoteSettings {
+	if typeName == "note" {
+		typeName = "default"
+	}
+	return vaultNotes[typeName]
+}
+
+func lookupNoteSpec(cmd *cobra.Command, typeName string) (noteSpec, error) {
+	fixed := func(render func() string) noteSpec {
+		return noteSpec{ext: ".md", next: func(string) (string, error) { return render(), nil }}
+	}
+
+	switch typeName {
+	case "default", "note":
+		return fixed(gen.Default), nil
+	case "daily":
+		return fixed(gen.Daily), nil
+	case "fleeting":
+		return fixed(gen.Fleeting), nil
+	case "voice":
+		return fixed(gen.Voice), nil
+	case "weekly":
+		return fixed(gen.Weekly), nil
+	case "monthly":
+		return fixed(gen.Monthly), nil
+	case "quarterly":
+		return fixed(gen.Quarterly), nil
+	case "yearly":
+		return fixed(gen.Yearly), nil
+	case "analog":
+		return noteSpec{ext: ".md", next: func(dir string) (string, error) {
+			date := gen.GetCurrentDate()
+			n, err := nextAnalog(cmd, dir, date)
+			if err != nil {
+				return "", err
+			}
+			return counter.FormatAnalog(date, n), nil
+		}}, nil
+	case "project":
+		return seqNoteSpec(sequential.Spec{Prefix: "P", Width: 4, Start: 1}, ".md"), nil
+	case "seq":
+		return seqNoteSpec(defaultSeqSpec(), ".md"), nil
+	}
+
+	noteType, ok := cfg.Types[typeName]
+	if !ok {
+		return noteSpec{}, fmt.Errorf("unknown note type: %s", typeName)
+	}
+	if err := noteType.Validate(); err != nil {
+		return noteSpec{}, fmt.Errorf("type %q: %w", typeName, err)
+	}
+
+	if noteType.Kind == config.KindSeq {
+		spec := sequential.Spec{Prefix: noteType.Prefix, Width: noteType.Width, Start: noteType.Start}
+		return seqNoteSpec(spec, noteType.FileExtension()), nil
+	}
+
+	render, err := obsidian.CompileFormat(noteType.Format)
+	if err != nil {
+		return noteSpec{}, fmt.Errorf("type %q: %w", typeName, err)
+	}
+	return noteSpec{ext: noteType.FileExtension(), next: func(string) (string, error) {
+		return gen.Render(generator.Formatter(render)), nil
+	}}, nil
+}
+
+func seqNoteSpec(spec sequential.Spec, ext string) noteSpec {
+	return noteSpec{ext: ext, titled: true, seq: &spec}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toto/stamp/internal/config"
+	"github.com/toto/stamp/internal/obsidian"
+	"github.com/toto/stamp/internal/sequential"
+)
+
+func TestNewRendersTemplate(t *testing.T) {
+	home := setupCLI(t)
+	gen.SetTime(time.Date(2025, 11, 12, 9, 30, 0, 0, time.UTC))
+
+	template := filepath.Join(home, "daily.md")
+	body := "# {{.ID}}\ntype: {{.Type}}\ntitle: {{.Title}}\nfile: {{.Filename}}\n"
+	if err := os.WriteFile(template, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runCLI(t, "new", "daily", "--template", template, "--dir", "journal", "Standup"); err != nil {
+		t.Fatalf("new daily error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(home, "journal", "2025-11-12.md"))
+	if err != nil {
+		t.Fatalf("note not created: %v", err)
+	}
+	want := "# 2025-11-12\ntype: daily\ntitle: Standup\nfile: 2025-11-12.md\n"
+	if string(data) != want {
+		t.Errorf("note = %q, want %q", data, want)
+	}
+
+	// Without a template the default front matter is used.
+	if err := runCLI(t, "new", "weekly"); err != nil {
+		t.Fatalf("new weekly error = %v", err)
+	}
+	data, err = os.ReadFile(filepath.Join(home, "2025-W46.md"))
+	if err != nil {
+		t.Fatalf("note not created: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "---\nid: 2025-W46\ntype: weekly\n") {
+		t.Errorf("default template = %q", data)
+	}
+}
+
+func TestNewRefusesOverwrite(t *testing.T) {
+	home := setupCLI(t)
+	gen.SetTime(time.Date(2025, 11, 12, 9, 30, 0, 0, time.UTC))
+
+	path := filepath.Join(home, "2025-11-12.md")
+	if err := os.WriteFile(path, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCLI(t, "new", "daily"); err == nil {
+		t.Fatal("new daily over an existing note error = nil")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep" {
+		t.Errorf("existing note changed to %q", data)
+	}
+}
+
+func TestNewReservesSequentialIDs(t *testing.T) {
+	home := setupCLI(t)
+	if err := os.WriteFile(filepath.Join(home, "P0007 Existing.md"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runCLI(t, "new", "project", "Plan"); err != nil {
+		t.Fatalf("new project error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "P0008 Plan.md")); err != nil {
+		t.Errorf("project note not created: %v", err)
+	}
+
+	cfg.Seq = config.SeqConfig{Prefix: "jin", Width: 3}
+	if err := runCLI(t, "new", "seq", "Notes"); err != nil {
+		t.Fatalf("new seq error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "jin001 Notes.md")); err != nil {
+		t.Errorf("seq note not created: %v", err)
+	}
+
+	placeholders, _ := filepath.Glob(filepath.Join(home, "*"+sequential.ReservedSuffix))
+	if len(placeholders) != 0 {
+		t.Errorf("placeholders left behind: %v", placeholders)
+	}
+
+	if err := runCLI(t, "new", "bogus"); err == nil || !strings.Contains(err.Error(), "unknown note type") {
+		t.Errorf("new bogus error = %v, want unknown note type", err)
+	}
+}
+
+func TestNewVaultStaysInsideVault(t *testing.T) {
+	home := setupCLI(t)
+	gen.SetTime(time.Date(2025, 11, 12, 9, 30, 0, 0, time.UTC))
+	vault = filepath.Join(home, "vault")
+	t.Cleanup(func() { vault, vaultNotes = "", nil })
+
+	if err := os.MkdirAll(filepath.Join(vault, "Templates"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vault, "Templates", "Daily.md"), []byte("# {{title}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "secret.md"), []byte("secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	vaultNotes = map[string]obsidian.NoteSettings{"daily": {Folder: "Journal", Template: "Templates/Daily"}}
+	if err := runCLI(t, "new", "daily", "--vault"); err != nil {
+		t.Fatalf("new daily --vault error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(vault, "Journal", "2025-11-12.md")); err != nil || string(data) != "# 2025-11-12\n" {
+		t.Errorf("vault note = %q, %v", data, err)
+	}
+
+	tests := []obsidian.NoteSettings{
+		{Folder: "../../x"},
+		{Folder: "Journal/../../x"},
+		{Folder: "Journal", Template: "../secret"},
+	}
+	for _, settings := range tests {
+		vaultNotes = map[string]obsidian.NoteSettings{"weekly": settings}
+		if err := runCLI(t, "new", "weekly", "--vault"); err == nil || !strings.Contains(err.Error(), "outside the vault") {
+			t.Errorf("new weekly --vault with %+v error = %v, want outside the vault", settings, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(home, "..", "x")); err == nil {
+		t.Error("folder outside the vault was created")
+	}
+	if _, err := os.Stat(filepath.Join(vault, "Journal", "2025-W46.md")); err == nil {
+		t.Error("note created from a template outside the vault")
+	}
+}
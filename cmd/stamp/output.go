@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toto/stamp/internal/clipboard"
+)
+
+// Output formats accepted by --format.
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatEnv  = "env"
+)
+
+// Result actions describing what a command did.
+const (
+	actionNext    = "next"
+	actionCheck   = "check"
+	actionCounter = "counter"
+	actionReset   = "reset"
+	actionCreate  = "create"
+	actionVersion = "version"
+	actionParse   = "parse"
+)
+
+// noteResult is the outcome of a command. In text mode it is rendered as the
+// familiar filename (or Text, for informational commands); with --format json
+// or env it is emitted as a structured record.
+type noteResult struct {
+	Type   string
+	Action string
+	ID     string
+	Title  string
+	// Name overrides the default "<ID> <Title>" name, for types whose
+	// filenames do not include the title.
+	Name string
+	// Ext is appended to build the filename; defaults to ".md".
+	Ext string
+	// Dir marks entries that are directories and therefore have no extension.
+	Dir bool
+	// Counter holds the numeric counter or sequence value, when meaningful.
+	Counter *int
+	// Prefix is the letter prefix of sequential IDs.
+	Prefix string
+	// Time overrides the reported date, e.g. with the moment a parsed ID encodes.
+	Time time.Time
+	// Path is the full path of a created file, or the vault-relative path of
+	// the note with --vault-path.
+	Path string
+	// Text replaces the name in text mode (counter prose, version info).
+	Text string
+}
+
+// structuredResult is the JSON shape of a noteResult.
+type structuredResult struct {
+	Type     string `json:"type"`
+	Action   string `json:"action"`
+	ID       string `json:"id,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Date     string `json:"date,omitempty"`
+	Vault    string `json:"vault,omitempty"`
+	Counter  *int   `json:"counter,omitempty"`
+	Prefix   string `json:"prefix,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Commit   string `json:"commit,omitempty"`
+	Built    string `json:"built,omitempty"`
+}
+
+func validateFormat(format string) error {
+	switch format {
+	case formatText, formatJSON, formatEnv:
+		return nil
+	}
+	return fmt.Errorf("invalid --format %q (want text, json or env)", format)
+}
+
+// name is the note name without extension: the ID plus an optional title.
+func (r noteResult) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return titledName(r.ID, r.Title)
+}
+
+// titledName joins id and title, rewriting the title with the configured
+// title policy so that it is safe to use in a filename.
+func titledName(id, title string) string {
+	title = titlePolicy.Apply(title)
+	if title == "" {
+		return id
+	}
+	return id + " " + title
+}
+
+func (r noteResult) filename() string {
+	if r.ID == "" || r.Dir {
+		return r.name()
+	}
+	ext := r.Ext
+	if ext == "" {
+		ext = ".md"
+	}
+	return r.name() + ext
+}
+
+// outputResult renders r according to --format.
+func outputResult(r noteResult) error {
+	if r.Action == "" {
+		r.Action = actionNext
+	}
+	if flagVaultPath && r.Action == actionNext && r.ID != "" && r.Path == "" {
+		if vault == "" {
+			return fmt.Errorf("--vault-path: not inside an Obsidian vault")
+		}
+		r.Path = vaultNote(r.Type).Path(r.filename())
+	}
+
+	if flagFormat == formatText {
+		return outputText(r)
+	}
+
+	if flagCopy && r.ID != "" {
+		copyText := r.name()
+		if r.Path != "" {
+			copyText = r.Path
+		}
+		if err := clipboard.Copy(copyText); err != nil {
+			fmt.Fprintf(os.Stderr, "clipboard error: %v\n", err)
+		}
+	}
+
+	record := r.structured()
+	if flagFormat == formatEnv {
+		for _, line := range record.envLines() {
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+func outputText(r noteResult) error {
+	if r.Text != "" {
+		fmt.Println(r.Text)
+		return nil
+	}
+	if r.Path != "" {
+		return printResult(r.Path)
+	}
+	if r.ID == "" {
+		return nil
+	}
+
+	result := r.name()
+	if flagExt && !r.Dir {
+		result = r.filename()
+	}
+	return printResult(result)
+}
+
+func (r noteResult) structured() structuredResult {
+	record := structuredResult{
+		Type:     r.Type,
+		Action:   r.Action,
+		ID:       r.ID,
+		Title:    r.Title,
+		Name:     r.name(),
+		Filename: r.filename(),
+		Path:     r.Path,
+		Vault:    vault,
+		Counter:  r.Counter,
+		Prefix:   r.Prefix,
+	}
+	if r.Action == actionVersion {
+		record.Version, record.Commit, record.Built = version, commit, date
+		record.Vault = ""
+	} else if !r.Time.IsZero() {
+		record.Date = r.Time.Format(time.RFC3339)
+	} else if r.Action != actionParse {
+		record.Date = gen.Now().Format(time.RFC3339)
+	}
+	return record
+}
+
+// envLines renders the record as shell-quoted STAMP_* assignments suitable
+// for eval.
+func (s structuredResult) envLines() []string {
+	pairs := [][2]string{
+		{"TYPE", s.Type},
+		{"ACTION", s.Action},
+		{"ID", s.ID},
+		{"TITLE", s.Title},
+		{"NAME", s.Name},
+		{"FILENAME", s.Filename},
+		{"PATH", s.Path},
+		{"DATE", s.Date},
+		{"VAULT", s.Vault},
+		{"PREFIX", s.Prefix},
+		{"VERSION", s.Version},
+		{"COMMIT", s.Commit},
+		{"BUILT", s.Built},
+	}
+	if s.Counter != nil {
+		pairs = append(pairs, [2]string{"COUNTER", strconv.Itoa(*s.Counter)})
+	}
+
+	var lines []string
+	for _, pair := range pairs {
+		if pair[1] == "" {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("STAMP_%s=%s", pair[0], shellQuote(pair[1])))
+	}
+	return lines
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// printResult writes result to stdout, copying it to the clipboard when requested.
+func printResult(result string) error {
+	if flagCopy {
+		if err := clipboard.Copy(result); err != nil {
+			// Fall back to stdout if clipboard fails
+			fmt.Println(result)
+			return fmt.Errorf("clipboard error: %w", err)
+		}
+		if !flagQuiet {
+			fmt.Println(result)
+			fmt.Println("Copied to clipboard!")
+		}
+	} else {
+		fmt.Println(result)
+	}
+
+	return nil
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/calendar"
+	"github.com/toto/stamp/internal/config"
+	"github.com/toto/stamp/internal/parser"
+	"github.com/toto/stamp/internal/sequential"
+)
+
+var parseCmd = &cobra.Command{
+	Use:   "parse <name>...",
+	Short: "Parse existing note names back into structured data",
+	Long: `Recognise note names produced by stamp and report their type, timestamp,
+sequence number, prefix and title.
+
+Names may be paths and may carry a title and file extension
+("P0395 New CLI Tool.md"). Formats detected in an Obsidian vault and
+sequential types declared in config.yaml are recognised alongside the
+built-in formats. Periods resolve to their first day, so 2025-W46 reports
+the start of that week.
+
+With --format json each name is printed as one JSON object per line.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p := newNoteParser()
+
+		failed := 0
+		for i, name := range args {
+			res, err := p.Parse(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed++
+				continue
+			}
+			if i > 0 && flagFormat == formatText {
+				fmt.Println()
+			}
+			if err := outputResult(parseResult(res)); err != nil {
+				return err
+			}
+		}
+
+		if failed > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d of %d names not recognised", failed, len(args))
+		}
+		return nil
+	},
+}
+
+// newNoteParser builds a parser matching the generator's timezone, week
+// start, vault layouts and configured sequential types.
+func newNoteParser() *parser.Parser {
+	p := parser.New(gen.Now().Location())
+	if cfg.WeekStart != "" {
+		if weekStart, err := calendar.ParseWeekday(cfg.WeekStart); err == nil {
+			p.SetWeekStart(weekStart)
+		}
+	}
+
+	if vaultLayouts.DefaultLayout != "" {
+		p.AddLayout("default", vaultLayouts.DefaultLayout)
+	}
+	if vaultLayouts.DailyLayout != "" {
+		p.AddLayout("daily", vaultLayouts.DailyLayout)
+	}
+	if vaultLayouts.Weekly != nil {
+		p.AddRenderer("weekly", vaultLayouts.Weekly)
+	}
+	if vaultLayouts.MonthlyLayout != "" {
+		p.AddLayout("monthly", vaultLayouts.MonthlyLayout)
+	}
+	if vaultLayouts.Quarterly != nil {
+		p.AddRenderer("quarterly", vaultLayouts.Quarterly)
+	}
+	if vaultLayouts.YearlyLayout != "" {
+		p.AddLayout("yearly", vaultLayouts.YearlyLayout)
+	}
+
+	names := make([]string, 0, len(cfg.Types))
+	for name := range cfg.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		noteType := cfg.Types[name]
+		if noteType.Kind != config.KindSeq || noteType.Validate() != nil {
+			continue
+		}
+		p.AddSequence(name, sequential.Spec{Prefix: noteType.Prefix, Width: noteType.Width, Start: noteType.Start})
+	}
+	return p
+}
+
+func parseResult(res parser.Result) noteResult {
+	result := noteResult{
+		Type:   res.Type,
+		Action: actionParse,
+		ID:     res.ID,
+		Title:  res.Title,
+		Ext:    res.Ext,
+		Prefix: res.Prefix,
+		Time:   res.Time,
+	}
+	if res.Title != "" {
+		// Report the name as found rather than rewriting it with the title policy.
+		result.Name = res.ID + " " + res.Title
+	}
+	if res.Sequence > 0 {
+		result.Counter = intPtr(res.Sequence)
+	}
+
+	lines := []string{"type:     " + res.Type, "id:       " + res.ID}
+	if !res.Time.IsZero() {
+		lines = append(lines, "date:     "+res.Time.Format(time.RFC3339))
+	}
+	if res.Prefix != "" {
+		lines = append(lines, "prefix:   "+res.Prefix)
+	}
+	if res.Sequence > 0 {
+		lines = append(lines, fmt.Sprintf("sequence: %d", res.Sequence))
+	}
+	if res.Title != "" {
+		lines = append(lines, "title:    "+res.Title)
+	}
+	if res.Ext != "" {
+		lines = append(lines, "ext:      "+res.Ext)
+	}
+	result.Text = strings.Join(lines, "\n")
+	return result
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/toto/stamp/internal/obsidian"
+)
+
+func TestParseVaultPeriodicFormats(t *testing.T) {
+	home := setupCLI(t)
+	plugin := filepath.Join(home, ".obsidian", "plugins", "periodic-notes")
+	if err := os.MkdirAll(plugin, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	settings := `{
+  "weekly": {"enabled": true, "format": "gggg-[Week] ww"},
+  "quarterly": {"enabled": true, "format": "YYYY [Q]Q"}
+}`
+	if err := os.WriteFile(filepath.Join(plugin, "data.json"), []byte(settings), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	detected, err := obsidian.DetectWith(home, obsidian.Options{})
+	if err != nil {
+		t.Fatalf("DetectWith() error = %v", err)
+	}
+	applyObsidianLayouts(detected.Layouts)
+	t.Cleanup(func() { vaultLayouts = obsidian.Layouts{} })
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		// gggg and ww are locale weeks, which start on Sunday.
+		{"2025-Week 46.md", []string{"type:     weekly", "id:       2025-Week 46", "date:     2025-11-09T00:00:00Z"}},
+		{"2025 Q4 Review.md", []string{"type:     quarterly", "id:       2025 Q4", "date:     2025-10-01T00:00:00Z", "title:    Review"}},
+	}
+	for _, tt := range tests {
+		out, err := captureCLI(t, "parse", tt.name)
+		if err != nil {
+			t.Fatalf("parse %s error = %v", tt.name, err)
+		}
+		for _, line := range tt.want {
+			if !strings.Contains(out, line+"\n") {
+				t.Errorf("parse %s =\n%s\nmissing %q", tt.name, out, line)
+			}
+		}
+	}
+}
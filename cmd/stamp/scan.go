@@ -0,0 +1,61 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/sequential"
+)
+
+var (
+	flagScanRecursive bool
+	flagScanMaxDepth  int
+	flagScanDirs      []string
+	flagScanIgnore    []string
+)
+
+// addScanFlags registers the directory scanning flags shared by every command
+// that derives sequential IDs from the filesystem.
+func addScanFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&flagScanRecursive, "recursive", "r", false, "Scan subdirectories for existing IDs")
+	cmd.Flags().IntVar(&flagScanMaxDepth, "max-depth", 0, "Maximum subdirectory depth for --recursive (0 = unlimited)")
+	cmd.Flags().StringArrayVar(&flagScanDirs, "scan-dir", nil, "Additional directory to scan for existing IDs (repeatable)")
+	cmd.Flags().StringArrayVar(&flagScanIgnore, "ignore", nil, "Glob of entries to skip while scanning (repeatable)")
+}
+
+// scanOptions merges the configured scan settings for prefix with any flags
+// given on cmd. Flags override scalar settings and extend list settings.
+func scanOptions(cmd *cobra.Command, prefix string) sequential.Scan {
+	if prefix == "" {
+		prefix = "P"
+	}
+
+	configured := cfg.ScanFor(prefix)
+	scan := sequential.Scan{
+		Recursive: configured.Recursive,
+		MaxDepth:  configured.MaxDepth,
+		Dirs:      append([]string(nil), configured.Dirs...),
+		Ignore:    append([]string(nil), configured.Ignore...),
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("recursive") {
+		scan.Recursive = flagScanRecursive
+	}
+	if flags.Changed("max-depth") {
+		scan.MaxDepth = flagScanMaxDepth
+		if scan.MaxDepth > 0 && !flags.Changed("recursive") {
+			scan.Recursive = true
+		}
+	}
+	for _, dir := range flagScanDirs {
+		// Flag paths are relative to where stamp is run, not the target directory.
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		scan.Dirs = append(scan.Dirs, dir)
+	}
+	scan.Ignore = append(scan.Ignore, flagScanIgnore...)
+
+	return scan
+}
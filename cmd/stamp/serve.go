@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/counter"
+	"github.com/toto/stamp/internal/sequential"
+)
+
+var (
+	flagServeListen string
+	flagServeToken  string
+)
+
+var serveCmd = &cobra.Command{
+	Use:          "serve",
+	SilenceUsage: true,
+	Short:        "Serve analog and sequential counters to other machines over HTTP",
+	Long: `Serve this machine's counters over an HTTP/JSON API so that a team gets
+globally unique analog, project and seq numbers.
+
+Clients point counter_server.url at the service. They still scan their own
+directories and the service never issues a number at or below what a client
+found on disk. When the service is unreachable, clients warn and fall back to
+their local counters. Pair it with counter_backend: sqlite for heavy use.`,
+	Example: `  stamp serve --listen :7777 --token s3cret
+
+  # on each laptop
+  stamp config set counter_server.url http://stamp.lan:7777
+  stamp config set counter_server.token s3cret`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token := cfg.CounterServer.Token
+		if cmd.Flags().Changed("token") {
+			token = flagServeToken
+		}
+
+		listener, err := net.Listen("tcp", flagServeListen)
+		if err != nil {
+			return err
+		}
+		if host, _, _ := net.SplitHostPort(flagServeListen); token == "" && !isLoopback(host) {
+			fmt.Fprintln(os.Stderr, "Warning: serving without --token; anyone who can reach this address can reset counters")
+		}
+
+		server := &http.Server{
+			Handler:           counter.NewHandler(cntr, token),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdown)
+		}()
+
+		if !flagQuiet {
+			fmt.Fprintf(os.Stderr, "Serving counters from %s on http://%s\n", cntr.Path(), listener.Addr())
+		}
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&flagServeListen, "listen", "127.0.0.1:7777", "Address to listen on")
+	serveCmd.Flags().StringVar(&flagServeToken, "token", "", "Bearer token clients must send (default counter_server.token)")
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sharedSeq names the counter server's counter for a sequential type.
+func sharedSeq(typeName string, spec sequential.Spec) counter.Seq {
+	width := spec.Width
+	if width <= 0 {
+		width = 4
+	}
+	return counter.Seq{Type: typeName, Prefix: normalizePrefix(spec), Width: width}
+}
+
+// nextShared combines localNext, the next value found by scanning, with the
+// counter server: check only looks, otherwise the number is issued.
+func nextShared(opts seqCommandOptions, localNext int, check bool) (int, error) {
+	seq := sharedSeq(opts.Type, opts.Spec)
+	if check {
+		shared, err := remote.GetSeq(seq)
+		if err != nil {
+			return 0, err
+		}
+		return max(localNext, shared+1), nil
+	}
+	return remote.NextSeq(seq, localNext-1)
+}
+
+// sharedIssue has reservations of a sequential type take their numbers from
+// the counter server; it is nil without one.
+func sharedIssue(typeName string, spec sequential.Spec) func(next int) (int, error) {
+	if remote == nil {
+		return nil
+	}
+	seq := sharedSeq(typeName, spec)
+	return func(next int) (int, error) {
+		return remote.NextSeq(seq, next-1)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/toto/stamp/internal/counter"
+)
+
+// useCounterServer points the CLI at a counter service whose project counter
+// has already issued last.
+func useCounterServer(t *testing.T, last int) *counter.Manager {
+	t.Helper()
+	shared, err := counter.Open(counter.BackendMemory, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last > 0 {
+		if _, err := shared.NextSeq(counter.Seq{Prefix: "P", Width: 4}, last-1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := httptest.NewServer(counter.NewHandler(shared, ""))
+	t.Cleanup(server.Close)
+
+	remote = counter.NewClient(server.URL, "", cntr)
+	counters = remote
+	t.Cleanup(func() { remote, counters = nil, cntr })
+	return shared
+}
+
+func TestReserveUsesCounterServer(t *testing.T) {
+	home := setupCLI(t)
+	shared := useCounterServer(t, 20)
+	if err := os.WriteFile(filepath.Join(home, "P0003 Local.md"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runCLI(t, "project", "--reserve", "Plan"); err != nil {
+		t.Fatalf("project --reserve error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "P0021 Plan.md")); err != nil {
+		t.Errorf("reserved entry not created from the shared counter: %v", err)
+	}
+
+	if err := runCLI(t, "new", "project", "Design"); err != nil {
+		t.Fatalf("new project error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "P0022 Design.md")); err != nil {
+		t.Errorf("new note not numbered by the shared counter: %v", err)
+	}
+
+	if value, _ := shared.GetSeq(counter.Seq{Prefix: "P"}); value != 22 {
+		t.Errorf("shared counter = %d, want 22", value)
+	}
+}
+
+func TestResetSharedSeq(t *testing.T) {
+	setupCLI(t)
+	if err := runCLI(t, "project", "--reset"); err == nil {
+		t.Error("project --reset without a counter server error = nil")
+	}
+
+	shared := useCounterServer(t, 20)
+	if err := runCLI(t, "project", "--reset"); err != nil {
+		t.Fatalf("project --reset error = %v", err)
+	}
+	if value, _ := shared.GetSeq(counter.Seq{Prefix: "P"}); value != 0 {
+		t.Errorf("shared counter = %d after reset, want 0", value)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/config"
+	"github.com/toto/stamp/internal/generator"
+	"github.com/toto/stamp/internal/obsidian"
+	"github.com/toto/stamp/internal/sequential"
+)
+
+// registerCustomTypes adds a subcommand for every note type declared under
+// `types` in the configuration. Invalid entries and names that clash with
+// built-in commands are reported on stderr and skipped.
+func registerCustomTypes(types map[string]config.NoteType) {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		cmd, err := newCustomTypeCommand(name, types[name])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Config warning: type %q: %v\n", name, err)
+			continue
+		}
+		rootCmd.AddCommand(cmd)
+	}
+}
+
+func newCustomTypeCommand(name string, noteType config.NoteType) (*cobra.Command, error) {
+	if name == "" || strings.ContainsAny(name, " \t") {
+		return nil, fmt.Errorf("type names must be a single word")
+	}
+	if existing, _, err := rootCmd.Find([]string{name}); err == nil && existing != rootCmd {
+		return nil, fmt.Errorf("conflicts with built-in command %q", existing.Name())
+	}
+	// cobra only adds these when the command runs, after custom types are
+	// registered, so Find does not see them yet.
+	if name == "help" || name == "completion" {
+		return nil, fmt.Errorf("conflicts with built-in command %q", name)
+	}
+	if err := noteType.Validate(); err != nil {
+		return nil, err
+	}
+
+	if noteType.Kind == config.KindSeq {
+		return newCustomSeqCommand(name, noteType), nil
+	}
+	return newCustomDateCommand(name, noteType)
+}
+
+func newCustomDateCommand(name string, noteType config.NoteType) (*cobra.Command, error) {
+	render, err := obsidian.CompileFormat(noteType.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	short := noteType.Description
+	if short == "" {
+		short = fmt.Sprintf("Generate %s note filename", name)
+	}
+
+	return &cobra.Command{
+		Use:   name,
+		Short: fmt.Sprintf("%s (%s)", short, noteType.Format),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return outputResult(noteResult{
+				Type: name,
+				ID:   gen.Render(generator.Formatter(render)),
+				Ext:  noteType.FileExtension(),
+			})
+		},
+	}, nil
+}
+
+func newCustomSeqCommand(name string, noteType config.NoteType) *cobra.Command {
+	spec := sequential.Spec{
+		Prefix: noteType.Prefix,
+		Width:  noteType.Width,
+		Start:  noteType.Start,
+	}
+
+	width := spec.Width
+	if width <= 0 {
+		width = 4
+	}
+	pattern := spec.Prefix + strings.Repeat("X", width)
+
+	short := noteType.Description
+	if short == "" {
+		short = fmt.Sprintf("Generate %s number", name)
+	}
+
+	var check, counter, reset bool
+	var reserve reserveFlags
+	cmd := &cobra.Command{
+		Use:   name + " [title]",
+		Short: fmt.Sprintf("%s (%s)", short, pattern),
+		Long:  fmt.Sprintf("Equivalent to `stamp seq --prefix %s --width %d`.", spec.Prefix, width),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec.Scan = scanOptions(cmd, spec.Prefix)
+			return runSeqCommand(seqCommandOptions{
+				Type:         name,
+				Spec:         spec,
+				CounterLabel: name,
+				Extension:    noteType.FileExtension(),
+				Check:        check,
+				Counter:      counter,
+				Reserve:      reserve.mode(),
+				Reset:        reset,
+				TitleArgs:    args,
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&check, "check", false, "Check next number without incrementing")
+	cmd.Flags().BoolVar(&counter, "counter", false, "Show highest existing number")
+	addReserveFlags(cmd, &reserve)
+	addSharedResetFlag(cmd, &reset)
+	addScanFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/toto/stamp/internal/config"
+)
+
+func TestCustomTypeNameClashes(t *testing.T) {
+	setupCLI(t)
+	noteType := config.NoteType{Kind: config.KindSeq, Prefix: "INT", Width: 3}
+
+	for _, name := range []string{"help", "completion", "daily", "config"} {
+		if _, err := newCustomTypeCommand(name, noteType); err == nil || !strings.Contains(err.Error(), "conflicts with built-in command") {
+			t.Errorf("newCustomTypeCommand(%q) error = %v, want a clash", name, err)
+		}
+	}
+	if _, err := newCustomTypeCommand("interview", noteType); err != nil {
+		t.Errorf("newCustomTypeCommand(interview) error = %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/toto/stamp/internal/sequential"
+)
+
+var (
+	flagZettelAfter     string
+	flagZettelBranch    string
+	flagZettelSeparator string
+)
+
+var zettelCmd = &cobra.Command{
+	Use:   "zettel [title]",
+	Short: "Generate Luhmann-style Folgezettel IDs (e.g. 21/3a7b)",
+	Long: `Generate hierarchical Folgezettel IDs that alternate numbers and letters.
+
+Without flags the next top-level number is returned. --after continues the
+train of an existing note with its next free sibling (1a -> 1b, 1a1 -> 1a2);
+--branch starts or extends a branch below a note (1 -> 1a, 1a -> 1a1).
+Existing IDs are discovered by scanning file and folder names, where "/" in
+an ID may be written as "." (21.3a7b).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagZettelAfter != "" && flagZettelBranch != "" {
+			return fmt.Errorf("--after and --branch cannot be combined")
+		}
+		if flagZettelSeparator != "" && flagZettelSeparator != "/" && flagZettelSeparator != "." {
+			return fmt.Errorf("invalid --separator %q (want / or .)", flagZettelSeparator)
+		}
+
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		scan := scanOptions(cmd, "zettel")
+		scan.Exclude = datedNoteMatcher()
+
+		var next sequential.Zettel
+		if flagZettelBranch != "" {
+			next, err = sequential.NextZettelChild(wd, flagZettelBranch, flagZettelSeparator, scan)
+		} else {
+			next, err = sequential.NextZettelSibling(wd, flagZettelAfter, scan)
+		}
+		if err != nil {
+			return err
+		}
+
+		return outputResult(noteResult{
+			Type:  "zettel",
+			ID:    next.String(),
+			Title: strings.Join(args, " "),
+		})
+	},
+}
+
+func init() {
+	zettelCmd.Flags().StringVar(&flagZettelAfter, "after", "", "Return the next sibling after this ID")
+	zettelCmd.Flags().StringVar(&flagZettelBranch, "branch", "", "Return the next child below this ID")
+	zettelCmd.Flags().StringVar(&flagZettelSeparator, "separator", "", "Separator to place after a top-level number when branching (/ or .)")
+	addScanFlags(zettelCmd)
+}
+
+// datedNoteMatcher reports names of dated notes, such as the yearly 2025.md
+// or a vault's 20251112.md daily note, so that they are not taken for
+// top-level Folgezettel.
+func datedNoteMatcher() func(name string) bool {
+	p := newNoteParser()
+	return func(name string) bool {
+		res, err := p.Parse(name)
+		return err == nil && !res.Time.IsZero()
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestZettelSkipsDatedNotes(t *testing.T) {
+	home := setupCLI(t)
+	for _, name := range []string{"1 Root.md", "2.md", "2025.md", "2024 Review.md", "2025-11-12.md"} {
+		if err := os.WriteFile(filepath.Join(home, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := captureCLI(t, "zettel")
+	if err != nil {
+		t.Fatalf("zettel error = %v", err)
+	}
+	if out != "3\n" {
+		t.Errorf("zettel = %q, want 3 with the yearly notes skipped", out)
+	}
+}
@@ -0,0 +1,101 @@
+// Package calendar provides week and quarter arithmetic shared by the
+// generator and the Obsidian format renderer.
+package calendar
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// ParseWeekday converts a weekday name or three-letter abbreviation
+// (case-insensitive) into a time.Weekday.
+func ParseWeekday(name string) (time.Weekday, error) {
+	if wd, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return wd, nil
+	}
+	return time.Sunday, fmt.Errorf("invalid weekday %q", name)
+}
+
+// ISOWeek returns the ISO 8601 week-numbering year and week of t.
+func ISOWeek(t time.Time) (year, week int) {
+	return t.ISOWeek()
+}
+
+// Week returns the week-numbering year and week of t for weeks starting on
+// start. A Monday start follows ISO 8601 (week 1 contains the first Thursday);
+// any other start uses the convention that week 1 contains January 1st, which
+// matches Moment.js locale weeks such as en-US.
+func Week(t time.Time, start time.Weekday) (year, week int) {
+	if start == time.Monday {
+		return t.ISOWeek()
+	}
+	return LocaleWeek(t, int(start), 6+int(start))
+}
+
+// WeekStart returns midnight in loc on the first day of the given week of a
+// week-numbering year, using the same conventions as Week. Out-of-range weeks
+// roll over into neighbouring years.
+func WeekStart(year, week int, start time.Weekday, loc *time.Location) time.Time {
+	dow, doy := int(start), 6+int(start)
+	if start == time.Monday {
+		doy = 4 // ISO 8601: January 4th is always in week 1
+	}
+	offset := firstWeekOffset(year, dow, doy)
+	return time.Date(year, time.January, 1+7*(week-1)+offset, 0, 0, 0, 0, loc)
+}
+
+// LocaleWeek implements Moment.js week-of-year arithmetic where dow is the
+// first day of the week (0 = Sunday) and doy is 7 + dow - janX, janX being the
+// January day that always falls in week 1.
+func LocaleWeek(t time.Time, dow, doy int) (year, week int) {
+	year = t.Year()
+	offset := firstWeekOffset(year, dow, doy)
+	days := t.YearDay() - offset - 1
+	week = days/7 + 1
+	if days < 0 {
+		// days is never below -7, so the floor division lands on week 0
+		week = 0
+	}
+
+	switch {
+	case week < 1:
+		year--
+		week += weeksInYear(year, dow, doy)
+	case week > weeksInYear(year, dow, doy):
+		week -= weeksInYear(year, dow, doy)
+		year++
+	}
+	return year, week
+}
+
+func firstWeekOffset(year, dow, doy int) int {
+	fwd := 7 + dow - doy
+	fwdlw := (7 + int(time.Date(year, time.January, fwd, 0, 0, 0, 0, time.UTC).Weekday()) - dow) % 7
+	return -fwdlw + fwd - 1
+}
+
+func weeksInYear(year, dow, doy int) int {
+	offset := firstWeekOffset(year, dow, doy)
+	nextOffset := firstWeekOffset(year+1, dow, doy)
+	return (daysInYear(year) - offset + nextOffset) / 7
+}
+
+func daysInYear(year int) int {
+	return time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// Quarter returns the calendar quarter (1-4) of t.
+func Quarter(t time.Time) int {
+	return (int(t.Month())-1)/3 + 1
+}
@@ -0,0 +1,100 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+}
+
+func TestWeek(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        time.Time
+		start    time.Weekday
+		wantYear int
+		wantWeek int
+	}{
+		{"iso mid year", date(2025, 11, 12), time.Monday, 2025, 46},
+		{"iso year end belongs to next year", date(2025, 12, 29), time.Monday, 2026, 1},
+		{"iso sunday before", date(2025, 12, 28), time.Monday, 2025, 52},
+		{"iso week 53", date(2021, 1, 1), time.Monday, 2020, 53},
+		{"sunday mid year", date(2025, 11, 12), time.Sunday, 2025, 46},
+		{"sunday year end rolls forward", date(2025, 12, 28), time.Sunday, 2026, 1},
+		{"sunday jan 1", date(2022, 1, 1), time.Sunday, 2022, 1},
+		{"sunday last week", date(2022, 12, 24), time.Sunday, 2022, 52},
+		{"saturday start", date(2025, 1, 3), time.Saturday, 2025, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			year, week := Week(tt.t, tt.start)
+			if year != tt.wantYear || week != tt.wantWeek {
+				t.Errorf("Week(%s, %s) = %d-%d, want %d-%d",
+					tt.t.Format("2006-01-02"), tt.start, year, week, tt.wantYear, tt.wantWeek)
+			}
+		})
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		year, week int
+		start      time.Weekday
+		want       string
+	}{
+		{2025, 1, time.Monday, "2024-12-30"},
+		{2025, 46, time.Monday, "2025-11-10"},
+		{2020, 53, time.Monday, "2020-12-28"},
+		{2022, 1, time.Sunday, "2021-12-26"},
+		{2025, 46, time.Sunday, "2025-11-09"},
+	}
+
+	for _, tt := range tests {
+		got := WeekStart(tt.year, tt.week, tt.start, time.UTC)
+		if got.Format("2006-01-02") != tt.want {
+			t.Errorf("WeekStart(%d, %d, %s) = %s, want %s", tt.year, tt.week, tt.start, got.Format("2006-01-02"), tt.want)
+		}
+		if year, week := Week(got, tt.start); year != tt.year || week != tt.week {
+			t.Errorf("Week(WeekStart(%d, %d, %s)) = %d-%d", tt.year, tt.week, tt.start, year, week)
+		}
+	}
+}
+
+func TestLocaleWeekMatchesISO(t *testing.T) {
+	day := date(2015, 1, 1)
+	for i := 0; i < 365*12; i++ {
+		isoYear, isoWeek := day.ISOWeek()
+		year, week := LocaleWeek(day, 1, 4)
+		if year != isoYear || week != isoWeek {
+			t.Fatalf("LocaleWeek(%s) = %d-%d, want %d-%d", day.Format("2006-01-02"), year, week, isoYear, isoWeek)
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+}
+
+func TestQuarter(t *testing.T) {
+	tests := map[time.Month]int{
+		time.January: 1, time.March: 1, time.April: 2,
+		time.June: 2, time.July: 3, time.October: 4, time.December: 4,
+	}
+	for month, want := range tests {
+		if got := Quarter(date(2025, month, 15)); got != want {
+			t.Errorf("Quarter(%s) = %d, want %d", month, got, want)
+		}
+	}
+}
+
+func TestParseWeekday(t *testing.T) {
+	if wd, err := ParseWeekday("Sunday"); err != nil || wd != time.Sunday {
+		t.Errorf("ParseWeekday(Sunday) = %v, %v", wd, err)
+	}
+	if wd, err := ParseWeekday("mon"); err != nil || wd != time.Monday {
+		t.Errorf("ParseWeekday(mon) = %v, %v", wd, err)
+	}
+	if _, err := ParseWeekday("someday"); err == nil {
+		t.Error("ParseWeekday(someday) expected error")
+	}
+}
@@ -0,0 +1,253 @@
+// Package clipboard copies generated names to the system clipboard using the
+// native macOS clipboard, Wayland/X11 command-line tools, or an OSC 52
+// terminal escape sequence for remote shells.
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Provider names accepted by SetProvider.
+const (
+	ProviderAuto    = "auto"
+	ProviderNative  = "native"
+	ProviderWayland = "wl-copy"
+	ProviderXclip   = "xclip"
+	ProviderXsel    = "xsel"
+	ProviderOSC52   = "osc52"
+)
+
+// Provider copies text to a clipboard.
+type Provider interface {
+	Name() string
+	Available() bool
+	Copy(text string) error
+}
+
+// reader is implemented by providers that can also read the clipboard.
+type reader interface {
+	Read() (string, error)
+}
+
+// Hooks replaced by tests to exercise provider selection headlessly.
+var (
+	lookPath     = exec.LookPath
+	getenv       = os.Getenv
+	runCommand   = execCommand
+	runCopy      = execCopy
+	openTerminal = func() (io.WriteCloser, error) {
+		return os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	}
+	// nativeClipboard is the platform clipboard, nil where none is built in.
+	nativeClipboard = platformNative()
+)
+
+var selected = ProviderAuto
+
+// SetProvider selects the clipboard provider by name. An empty name or
+// "auto" picks the first available provider for the current session.
+func SetProvider(name string) error {
+	if name == "" {
+		name = ProviderAuto
+	}
+	if name != ProviderAuto && name != ProviderNative && providerByName(name) == nil {
+		return fmt.Errorf("unknown clipboard provider %q (want auto, native, wl-copy, xclip, xsel or osc52)", name)
+	}
+	selected = name
+	return nil
+}
+
+// Copy copies text to the clipboard
+func Copy(text string) error {
+	provider, err := resolve()
+	if err != nil {
+		return err
+	}
+	return provider.Copy(text)
+}
+
+// Read reads text from the clipboard
+func Read() (string, error) {
+	provider, err := resolve()
+	if err != nil {
+		return "", err
+	}
+	if r, ok := provider.(reader); ok {
+		return r.Read()
+	}
+	return "", fmt.Errorf("clipboard provider %s cannot read the clipboard", provider.Name())
+}
+
+func resolve() (Provider, error) {
+	if selected != ProviderAuto {
+		provider := providerByName(selected)
+		if provider == nil || !provider.Available() {
+			return nil, fmt.Errorf("clipboard provider %s is not available", selected)
+		}
+		return provider, nil
+	}
+
+	for _, provider := range autoOrder() {
+		if provider.Available() {
+			return provider, nil
+		}
+	}
+	return nil, errors.New("no clipboard provider available (install wl-clipboard, xclip or xsel, or use a terminal with OSC 52 support)")
+}
+
+// autoOrder prefers local clipboards, except in SSH sessions where the local
+// machine's clipboard is reached through OSC 52 instead.
+func autoOrder() []Provider {
+	var order []Provider
+	remote := getenv("SSH_TTY") != "" || getenv("SSH_CONNECTION") != ""
+
+	if remote {
+		order = append(order, osc52Provider{})
+	}
+	if nativeClipboard != nil {
+		order = append(order, nativeClipboard)
+	}
+	order = append(order, commandProviders()...)
+	if !remote {
+		order = append(order, osc52Provider{})
+	}
+	return order
+}
+
+func providerByName(name string) Provider {
+	if name == ProviderNative {
+		return nativeClipboard
+	}
+	if name == ProviderOSC52 {
+		return osc52Provider{}
+	}
+	for _, provider := range commandProviders() {
+		if provider.Name() == name {
+			return provider
+		}
+	}
+	return nil
+}
+
+func commandProviders() []Provider {
+	return []Provider{
+		commandProvider{
+			name: ProviderWayland, env: "WAYLAND_DISPLAY",
+			copyCmd: []string{"wl-copy"}, readCmd: []string{"wl-paste", "--no-newline"},
+		},
+		commandProvider{
+			name: ProviderXclip, env: "DISPLAY",
+			copyCmd: []string{"xclip", "-selection", "clipboard"}, readCmd: []string{"xclip", "-selection", "clipboard", "-o"},
+		},
+		commandProvider{
+			name: ProviderXsel, env: "DISPLAY",
+			copyCmd: []string{"xsel", "--clipboard", "--input"}, readCmd: []string{"xsel", "--clipboard", "--output"},
+		},
+	}
+}
+
+// commandProvider pipes text into an external clipboard tool.
+type commandProvider struct {
+	name    string
+	env     string // display variable the tool needs
+	copyCmd []string
+	readCmd []string
+}
+
+func (p commandProvider) Name() string { return p.name }
+
+func (p commandProvider) Available() bool {
+	if getenv(p.env) == "" {
+		return false
+	}
+	_, err := lookPath(p.copyCmd[0])
+	return err == nil
+}
+
+func (p commandProvider) Copy(text string) error {
+	return runCopy(text, p.copyCmd[0], p.copyCmd[1:]...)
+}
+
+func (p commandProvider) Read() (string, error) {
+	return runCommand("", p.readCmd[0], p.readCmd[1:]...)
+}
+
+// execCopy runs a copy command without pipes on its output: xclip, and
+// wl-copy without --foreground, fork a process that keeps the selection, and
+// Run would wait for it to close inherited pipes until another application
+// takes the clipboard. Error messages go through a temporary file instead.
+func execCopy(stdin, name string, args ...string) error {
+	stderr, err := os.CreateTemp("", "stamp-clipboard-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(stderr.Name())
+	defer stderr.Close()
+
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if msg, _ := os.ReadFile(stderr.Name()); len(bytes.TrimSpace(msg)) > 0 {
+			return fmt.Errorf("%s: %w: %s", name, err, bytes.TrimSpace(msg))
+		}
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// execCommand runs a command and returns its output, for reading the
+// clipboard.
+func execCommand(stdin, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+// osc52Provider asks the terminal emulator to set the clipboard, which works
+// across SSH sessions when the terminal supports OSC 52.
+type osc52Provider struct{}
+
+func (osc52Provider) Name() string { return ProviderOSC52 }
+
+func (osc52Provider) Available() bool {
+	term := getenv("TERM")
+	return term != "" && term != "dumb"
+}
+
+func (osc52Provider) Copy(text string) error {
+	tty, err := openTerminal()
+	if err != nil {
+		return fmt.Errorf("osc52: %w", err)
+	}
+	defer tty.Close()
+
+	_, err = io.WriteString(tty, osc52Sequence(text, getenv("TMUX") != ""))
+	return err
+}
+
+// osc52Sequence builds the escape sequence, wrapping it in a DCS passthrough
+// when running inside tmux.
+func osc52Sequence(text string, tmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if tmux {
+		return "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	return seq
+}
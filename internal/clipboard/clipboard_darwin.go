@@ -9,26 +9,39 @@ import (
 	"golang.design/x/clipboard"
 )
 
+var initErr error
+
 // Initialize the clipboard package
 func init() {
 	// Initialize clipboard access
-	err := clipboard.Init()
-	if err != nil {
+	initErr = clipboard.Init()
+	if initErr != nil {
 		// Log warning but don't fail
-		fmt.Printf("Warning: clipboard initialization failed: %v\n", err)
+		fmt.Printf("Warning: clipboard initialization failed: %v\n", initErr)
 	}
 }
 
+func platformNative() Provider {
+	return nativeProvider{}
+}
+
+// nativeProvider uses the macOS pasteboard.
+type nativeProvider struct{}
+
+func (nativeProvider) Name() string { return ProviderNative }
+
+func (nativeProvider) Available() bool { return initErr == nil }
+
 // Copy copies text to the clipboard
-func Copy(text string) error {
+func (nativeProvider) Copy(text string) error {
 	// Write to clipboard
 	clipboard.Write(clipboard.FmtText, []byte(text))
 	return nil
 }
 
 // Read reads text from the clipboard
-func Read() (string, error) {
+func (nativeProvider) Read() (string, error) {
 	// Read from clipboard
 	data := clipboard.Read(clipboard.FmtText)
 	return string(data), nil
-}
\ No newline at end of file
+}
@@ -3,14 +3,8 @@
 
 package clipboard
 
-import "fmt"
-
-// Copy copies text to the clipboard
-func Copy(text string) error {
-	return fmt.Errorf("clipboard copy is only supported on macOS")
-}
-
-// Read reads text from the clipboard
-func Read() (string, error) {
-	return "", fmt.Errorf("clipboard read is only supported on macOS")
+// platformNative returns nil: outside macOS the clipboard is reached through
+// command-line tools or OSC 52.
+func platformNative() Provider {
+	return nil
 }
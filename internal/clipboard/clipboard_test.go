@@ -0,0 +1,286 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+type commandCall struct {
+	stdin string
+	name  string
+	args  []string
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// fakeSession swaps the package hooks for a headless session with the given
+// environment and installed binaries, and records every command invocation.
+type fakeSession struct {
+	calls    []commandCall
+	terminal bytes.Buffer
+	output   string
+}
+
+func newFakeSession(t *testing.T, env map[string]string, bins ...string) *fakeSession {
+	t.Helper()
+
+	origLookPath, origGetenv, origRun, origCopy := lookPath, getenv, runCommand, runCopy
+	origTerminal, origNative, origSelected := openTerminal, nativeClipboard, selected
+	t.Cleanup(func() {
+		lookPath, getenv, runCommand, runCopy = origLookPath, origGetenv, origRun, origCopy
+		openTerminal, nativeClipboard, selected = origTerminal, origNative, origSelected
+	})
+
+	s := &fakeSession{}
+	installed := map[string]bool{}
+	for _, bin := range bins {
+		installed[bin] = true
+	}
+
+	getenv = func(key string) string { return env[key] }
+	lookPath = func(file string) (string, error) {
+		if installed[file] {
+			return "/usr/bin/" + file, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	runCommand = func(stdin, name string, args ...string) (string, error) {
+		s.calls = append(s.calls, commandCall{stdin: stdin, name: name, args: args})
+		return s.output, nil
+	}
+	runCopy = func(stdin, name string, args ...string) error {
+		_, err := runCommand(stdin, name, args...)
+		return err
+	}
+	openTerminal = func() (io.WriteCloser, error) {
+		return nopCloser{&s.terminal}, nil
+	}
+	nativeClipboard = nil
+	selected = ProviderAuto
+	return s
+}
+
+func TestCopy_AutoSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		bins     []string
+		wantCmd  string
+		wantOSC  bool
+		wantFail bool
+	}{
+		{
+			name:    "wayland prefers wl-copy",
+			env:     map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			bins:    []string{"wl-copy", "xclip"},
+			wantCmd: "wl-copy",
+		},
+		{
+			name:    "x11 uses xclip",
+			env:     map[string]string{"DISPLAY": ":0"},
+			bins:    []string{"wl-copy", "xclip", "xsel"},
+			wantCmd: "xclip",
+		},
+		{
+			name:    "x11 falls back to xsel",
+			env:     map[string]string{"DISPLAY": ":0"},
+			bins:    []string{"xsel"},
+			wantCmd: "xsel",
+		},
+		{
+			name:    "no display falls back to osc52",
+			env:     map[string]string{"TERM": "xterm-256color"},
+			bins:    []string{"xclip"},
+			wantOSC: true,
+		},
+		{
+			name:    "ssh prefers osc52 over forwarded X",
+			env:     map[string]string{"SSH_TTY": "/dev/pts/1", "DISPLAY": "localhost:10.0", "TERM": "xterm"},
+			bins:    []string{"xclip"},
+			wantOSC: true,
+		},
+		{
+			name:     "nothing available",
+			env:      map[string]string{"TERM": "dumb"},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeSession(t, tt.env, tt.bins...)
+
+			err := Copy("2025-11-12-A3")
+			if tt.wantFail {
+				if err == nil {
+					t.Fatal("Copy() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+
+			if tt.wantOSC {
+				if len(s.calls) != 0 {
+					t.Errorf("unexpected commands: %+v", s.calls)
+				}
+				if want := osc52Sequence("2025-11-12-A3", false); s.terminal.String() != want {
+					t.Errorf("terminal = %q, want %q", s.terminal.String(), want)
+				}
+				return
+			}
+
+			if len(s.calls) != 1 {
+				t.Fatalf("got %d commands, want 1: %+v", len(s.calls), s.calls)
+			}
+			if s.calls[0].name != tt.wantCmd {
+				t.Errorf("command = %s, want %s", s.calls[0].name, tt.wantCmd)
+			}
+			if s.calls[0].stdin != "2025-11-12-A3" {
+				t.Errorf("stdin = %q, want %q", s.calls[0].stdin, "2025-11-12-A3")
+			}
+		})
+	}
+}
+
+func TestCopy_NativePreferredLocally(t *testing.T) {
+	s := newFakeSession(t, map[string]string{"DISPLAY": ":0"}, "xclip")
+	native := &fakeProvider{name: ProviderNative, available: true}
+	nativeClipboard = native
+
+	if err := Copy("P0395"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if native.copied != "P0395" {
+		t.Errorf("native copied %q, want %q", native.copied, "P0395")
+	}
+	if len(s.calls) != 0 {
+		t.Errorf("unexpected commands: %+v", s.calls)
+	}
+}
+
+func TestSetProvider(t *testing.T) {
+	s := newFakeSession(t, map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, "wl-copy", "xsel")
+
+	if err := SetProvider("xsel"); err != nil {
+		t.Fatalf("SetProvider() error = %v", err)
+	}
+	if err := Copy("note"); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if len(s.calls) != 1 || s.calls[0].name != "xsel" {
+		t.Fatalf("calls = %+v, want a single xsel call", s.calls)
+	}
+	if got := strings.Join(s.calls[0].args, " "); got != "--clipboard --input" {
+		t.Errorf("xsel args = %q", got)
+	}
+
+	if err := SetProvider("xclip"); err != nil {
+		t.Fatalf("SetProvider() error = %v", err)
+	}
+	if err := Copy("note"); err == nil {
+		t.Error("Copy() with uninstalled xclip error = nil, want error")
+	}
+
+	if err := SetProvider("pbcopy"); err == nil {
+		t.Error("SetProvider(pbcopy) error = nil, want error")
+	}
+	if err := SetProvider(""); err != nil || selected != ProviderAuto {
+		t.Errorf("SetProvider(\"\") = %v, selected = %q", err, selected)
+	}
+}
+
+func TestRead_CommandProvider(t *testing.T) {
+	s := newFakeSession(t, map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, "wl-copy")
+	s.output = "2025-W46"
+
+	got, err := Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got != "2025-W46" {
+		t.Errorf("Read() = %q, want %q", got, "2025-W46")
+	}
+	if s.calls[0].name != "wl-paste" {
+		t.Errorf("command = %s, want wl-paste", s.calls[0].name)
+	}
+
+	if err := SetProvider(ProviderOSC52); err != nil {
+		t.Fatal(err)
+	}
+	getenv = func(key string) string {
+		if key == "TERM" {
+			return "xterm"
+		}
+		return ""
+	}
+	if _, err := Read(); err == nil {
+		t.Error("Read() via osc52 error = nil, want error")
+	}
+}
+
+func TestCopy_CommandError(t *testing.T) {
+	newFakeSession(t, map[string]string{"DISPLAY": ":0"}, "xclip")
+	runCommand = func(stdin, name string, args ...string) (string, error) {
+		return "", errors.New("xclip: exit status 1")
+	}
+
+	if err := Copy("note"); err == nil {
+		t.Error("Copy() error = nil, want command error")
+	}
+}
+
+func TestOSC52Sequence(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("P0396 Design"))
+
+	if got, want := osc52Sequence("P0396 Design", false), "\x1b]52;c;"+encoded+"\x07"; got != want {
+		t.Errorf("osc52Sequence() = %q, want %q", got, want)
+	}
+	if got, want := osc52Sequence("P0396 Design", true), "\x1bPtmux;\x1b\x1b]52;c;"+encoded+"\x07\x1b\\"; got != want {
+		t.Errorf("osc52Sequence(tmux) = %q, want %q", got, want)
+	}
+}
+
+type fakeProvider struct {
+	name      string
+	available bool
+	copied    string
+}
+
+func (p *fakeProvider) Name() string    { return p.name }
+func (p *fakeProvider) Available() bool { return p.available }
+
+func (p *fakeProvider) Copy(text string) error {
+	p.copied = text
+	return nil
+}
+
+func TestExecCopy_BackgroundProcess(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh unavailable")
+	}
+
+	// Like xclip, the command leaves a process behind that holds the
+	// selection; Copy must not wait for it.
+	start := time.Now()
+	if err := execCopy("note", "sh", "-c", "cat >/dev/null; sleep 10 &"); err != nil {
+		t.Fatalf("execCopy() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("execCopy() waited %v for the background process", elapsed)
+	}
+
+	err := execCopy("note", "sh", "-c", "echo 'cannot open display' >&2; exit 1")
+	if err == nil || !strings.Contains(err.Error(), "cannot open display") {
+		t.Errorf("execCopy() error = %v, want the command's message", err)
+	}
+}
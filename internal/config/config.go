@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +14,97 @@ type Config struct {
 	Timezone        string `yaml:"timezone"`
 	AlwaysExtension bool   `yaml:"always_extension"`
 	CounterFile     string `yaml:"counter_file"`
+	WeekStart       string `yaml:"week_start"`
+
+	// CounterBackend stores analog counters in a json file (default), an
+	// sqlite database or memory.
+	CounterBackend string `yaml:"counter_backend,omitempty"`
+
+	// AnalogCounter selects how analog numbers are derived: file (the
+	// counter file), scan (existing notes) or hybrid (the larger of both).
+	AnalogCounter string `yaml:"analog_counter,omitempty"`
+
+	// CounterServer points analog, project and seq numbering at a shared
+	// `stamp serve` instance.
+	CounterServer CounterServerConfig `yaml:"counter_server,omitempty"`
+
+	// CounterRetention archives analog counters older than this age (e.g.
+	// 90d, 12w, 1y) whenever a number is issued. Empty keeps them forever.
+	CounterRetention string `yaml:"counter_retention,omitempty"`
+
+	// Clipboard selects the --copy provider: auto, native, wl-copy, xclip,
+	// xsel or osc52.
+	Clipboard string `yaml:"clipboard,omitempty"`
+
+	// Types declares additional note types keyed by subcommand name.
+	Types map[string]NoteType `yaml:"types,omitempty"`
+
+	// Templates maps note type names to text/template files used by `stamp new`.
+	Templates map[string]string `yaml:"templates,omitempty"`
+
+	// Seq sets the defaults of `stamp seq` when flags are omitted.
+	Seq SeqConfig `yaml:"seq,omitempty"`
+
+	// Title controls how titles are written into filenames.
+	Title TitleConfig `yaml:"title,omitempty"`
+
+	// Scan configures where sequential IDs are searched, keyed by prefix
+	// (case-insensitive). The "*" entry applies to prefixes without their own.
+	Scan map[string]ScanConfig `yaml:"scan,omitempty"`
+
+	// Obsidian controls how formats found in an Obsidian vault are used.
+	Obsidian ObsidianConfig `yaml:"obsidian,omitempty"`
+
+	files   []string          // merged files, global first
+	origins map[string]Source // dotted key -> file that last set it
+	ignored []Problem         // global-only keys found in local files
+}
+
+// CounterServerConfig locates a `stamp serve` counter service.
+type CounterServerConfig struct {
+	URL   string `yaml:"url,omitempty"`
+	Token string `yaml:"token,omitempty"`
+}
+
+// SeqConfig holds defaults for the `seq` command.
+type SeqConfig struct {
+	Prefix string `yaml:"prefix,omitempty"`
+	Width  int    `yaml:"width,omitempty"`
+	Start  int    `yaml:"start,omitempty"`
+}
+
+// TitleConfig is the filename policy for note titles; see naming.TitlePolicy.
+type TitleConfig struct {
+	Style         string `yaml:"style,omitempty"`
+	Transliterate bool   `yaml:"transliterate,omitempty"`
+	MaxLength     int    `yaml:"max_length,omitempty"`
+	Target        string `yaml:"target,omitempty"`
+	Replacement   string `yaml:"replacement,omitempty"`
+}
+
+// ScanConfig controls directory scanning for a sequential prefix.
+type ScanConfig struct {
+	Recursive bool     `yaml:"recursive,omitempty"`
+	MaxDepth  int      `yaml:"max_depth,omitempty"`
+	Dirs      []string `yaml:"dirs,omitempty"`
+	Ignore    []string `yaml:"ignore,omitempty"`
+}
+
+// ObsidianConfig controls the Obsidian integration.
+type ObsidianConfig struct {
+	// Strict rejects vault formats with unsupported or ambiguous tokens,
+	// reporting them and keeping the built-in formats instead.
+	Strict bool `yaml:"strict,omitempty"`
+}
+
+// ScanFor returns the scan settings for prefix, falling back to the "*" entry.
+func (c *Config) ScanFor(prefix string) ScanConfig {
+	for key, scan := range c.Scan {
+		if strings.EqualFold(key, prefix) {
+			return scan
+		}
+	}
+	return c.Scan["*"]
 }
 
 // Default returns the default configuration
@@ -22,59 +114,288 @@ func Default() *Config {
 	return &Config{
 		Timezone:        "", // Empty means use system timezone
 		AlwaysExtension: false,
-		CounterFile:     filepath.Join(home, ".stamp", "counters.json"),
+		CounterFile:     filepath.Join(stateDir(home), "counters.json"),
+		WeekStart:       "monday", // ISO 8601 weeks
 	}
 }
 
-// Load loads configuration from ~/.stamp/config.yaml
+// LocalConfigName is the per-directory configuration file discovered by
+// walking up from the working directory.
+const LocalConfigName = ".stamp.yaml"
+
+// Load loads the global configuration file (see Path) and merges any
+// .stamp.yaml files found between the filesystem root and the working
+// directory over it, the closest file winning. STAMP_* environment
+// variables override the result.
 func Load() (*Config, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = ""
+	}
+	return LoadFrom(wd)
+}
+
+// LoadFrom is Load with local file discovery starting at dir. An empty dir
+// loads only the global file.
+func LoadFrom(dir string) (*Config, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
 	}
 
-	configFile := filepath.Join(home, ".stamp", "config.yaml")
+	configFile, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := LoadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check if config file exists
-	if _, err := os.Stat(configFile); os.IsNotExist(err) {
-		// Use defaults if config doesn't exist
-		return Default(), nil
+	for _, local := range FindLocal(dir) {
+		// Relative paths in local files are relative to the file itself.
+		if err := cfg.merge(local, home, filepath.Dir(local)); err != nil {
+			return nil, err
+		}
 	}
 
-	// Read config file
-	data, err := os.ReadFile(configFile)
+	cfg.applyEnv(home)
+	return cfg, nil
+}
+
+// LoadFile loads a single configuration file over the defaults, without
+// discovering local files. A missing file yields the defaults.
+func LoadFile(path string) (*Config, error) {
+	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
 	}
 
 	// Start with defaults
 	cfg := Default()
-
-	// Parse YAML and overlay on defaults
-	if err := yaml.Unmarshal(data, cfg); err != nil {
+	cfg.origins = map[string]Source{}
+	if err := cfg.merge(path, home, ""); err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
+	return cfg, nil
+}
 
-	// Expand counter file path if it starts with ~
-	if strings.HasPrefix(cfg.CounterFile, "~/") || strings.HasPrefix(cfg.CounterFile, "~\\") {
-		cfg.CounterFile = filepath.Join(home, cfg.CounterFile[2:])
+// FindLocal returns the .stamp.yaml files in dir and its parents, outermost
+// first.
+func FindLocal(dir string) []string {
+	if dir == "" {
+		return nil
+	}
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return nil
 	}
 
-	return cfg, nil
+	var found []string
+	for {
+		candidate := filepath.Join(current, LocalConfigName)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			found = append([]string{candidate}, found...)
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return found
+		}
+		current = parent
+	}
+}
+
+// merge overlays the YAML file at path onto c, recording where each key came
+// from. Paths in the file are expanded against home and, when base is set,
+// made absolute relative to base. Base is set for local files, which cannot
+// set global-only keys.
+func (c *Config) merge(path, home, base string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return c.mergeData(path, data, home, base)
+}
+
+// mergeData is merge with the contents of path already read.
+func (c *Config) mergeData(path string, data []byte, home, base string) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		// Empty file
+		return nil
+	}
+	if base != "" {
+		c.ignored = append(c.ignored, dropGlobalOnly(doc.Content[0], path)...)
+		c.ignored = append(c.ignored, dropOutsideTemplates(doc.Content[0], path, home, base)...)
+	}
+
+	// Parse YAML and overlay on the configuration so far
+	if err := doc.Decode(c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	layer := map[string]Source{}
+	recordOrigins(doc.Content[0], "", path, layer)
+	for key := range layer {
+		if isMapEntry(key) {
+			// Map entries are replaced wholesale, so forget where their old
+			// fields came from.
+			for old := range c.origins {
+				if strings.HasPrefix(old, key+".") {
+					delete(c.origins, old)
+				}
+			}
+		}
+	}
+	for key, source := range layer {
+		c.origins[key] = source
+	}
+	c.files = append(c.files, path)
+
+	// Expand paths that start with ~
+	if _, ok := layer["counter_file"]; ok {
+		c.CounterFile = resolvePath(c.CounterFile, home, base)
+	}
+	for name, path := range c.Templates {
+		if _, ok := layer["templates."+name]; ok {
+			c.Templates[name] = resolvePath(path, home, base)
+		}
+	}
+	for prefix, scan := range c.Scan {
+		if _, ok := layer["scan."+prefix]; !ok {
+			continue
+		}
+		for i, dir := range scan.Dirs {
+			scan.Dirs[i] = resolvePath(dir, home, base)
+		}
+		c.Scan[prefix] = scan
+	}
+	return nil
+}
+
+// globalOnly are the keys a .stamp.yaml cannot set: a file that came with a
+// cloned repository must not choose where stamp sends the user's token or
+// which files it overwrites with counters.
+var globalOnly = map[string]bool{
+	"counter_server":  true,
+	"counter_file":    true,
+	"counter_backend": true,
+}
+
+// dropGlobalOnly removes the global-only keys from the local file's mapping,
+// reporting each.
+func dropGlobalOnly(node *yaml.Node, path string) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var problems []Problem
+	kept := node.Content[:0]
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if globalOnly[key.Value] {
+			problems = append(problems, Problem{File: path, Line: key.Line, Key: key.Value,
+				Message: "only the global config file can set this; ignored"})
+			continue
+		}
+		kept = append(kept, key, node.Content[i+1])
+	}
+	node.Content = kept
+	return problems
+}
+
+// dropOutsideTemplates removes the templates of a local file that lie outside
+// base, its directory, reporting each: stamp new copies a template into the
+// note, so a cloned repository must not point it at files such as ~/.ssh keys.
+func dropOutsideTemplates(node *yaml.Node, path, home, base string) []Problem {
+	templates := child(node, "templates")
+	if templates == nil || templates.Kind != yaml.MappingNode {
+		return nil
+	}
+	var problems []Problem
+	kept := templates.Content[:0]
+	for i := 0; i+1 < len(templates.Content); i += 2 {
+		key, value := templates.Content[i], templates.Content[i+1]
+		if value.Kind == yaml.ScalarNode && value.Value != "" && !within(base, resolvePath(value.Value, home, base)) {
+			problems = append(problems, Problem{File: path, Line: key.Line, Key: "templates." + key.Value,
+				Message: "a local config file can only use templates in its own directory; ignored"})
+			continue
+		}
+		kept = append(kept, key, value)
+	}
+	templates.Content = kept
+	return problems
+}
+
+// within reports whether path is dir or lies below it, following symlinks
+// that exist.
+func within(dir, path string) bool {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Ignored reports the keys that local files tried to set but cannot.
+func (c *Config) Ignored() []Problem {
+	return c.ignored
+}
+
+// mapSections are the top-level keys holding maps, whose entries are
+// replaced rather than merged field by field.
+var mapSections = map[string]bool{"types": true, "templates": true, "scan": true}
+
+func isMapEntry(key string) bool {
+	section, rest, ok := strings.Cut(key, ".")
+	return ok && mapSections[section] && !strings.Contains(rest, ".")
+}
+
+func recordOrigins(node *yaml.Node, prefix, file string, origins map[string]Source) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		path := key.Value
+		if prefix != "" {
+			path = prefix + "." + key.Value
+		}
+		origins[path] = Source{File: file, Line: key.Line}
+		recordOrigins(value, path, file, origins)
+	}
+}
+
+func resolvePath(path, home, base string) string {
+	path = expandHome(path, home)
+	if base != "" && path != "" && !filepath.IsAbs(path) {
+		return filepath.Join(base, path)
+	}
+	return path
+}
+
+func expandHome(path, home string) string {
+	if strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~\\") {
+		return filepath.Join(home, path[2:])
+	}
+	return path
 }
 
 // Save saves the configuration to file
 func (c *Config) Save() error {
-	home, err := os.UserHomeDir()
+	configFile, err := Path()
 	if err != nil {
 		return err
 	}
 
-	configDir := filepath.Join(home, ".stamp")
-	configFile := filepath.Join(configDir, "config.yaml")
-
 	// Ensure directory exists
-	if err := os.MkdirAll(configDir, 0o755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(configFile), 0o755); err != nil {
 		return err
 	}
 
@@ -35,6 +35,11 @@ func withHomeDir(t *testing.T, dir string) {
 
 	setEnv("HOME", dir)
 
+	// Keep the developer's environment from redirecting config and state.
+	for _, key := range []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME", EnvConfig, EnvCounterFile, EnvTimezone, EnvWeekStart} {
+		t.Setenv(key, "")
+	}
+
 	// On Windows, os.UserHomeDir falls back to USERPROFILE or HOMEDRIVE+HOMEPATH.
 	if runtime.GOOS == "windows" {
 		setEnv("USERPROFILE", dir)
@@ -69,6 +74,7 @@ func setupTempHome(t *testing.T) string {
 }
 
 func TestDefault(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "")
 	cfg := Default()
 
 	if cfg == nil {
@@ -83,6 +89,10 @@ func TestDefault(t *testing.T) {
 		t.Errorf("Default AlwaysExtension = %v, want false", cfg.AlwaysExtension)
 	}
 
+	if cfg.WeekStart != "monday" {
+		t.Errorf("Default WeekStart = %v, want monday", cfg.WeekStart)
+	}
+
 	home, _ := os.UserHomeDir()
 	expectedCounterFile := filepath.Join(home, ".stamp", "counters.json")
 	if cfg.CounterFile != expectedCounterFile {
@@ -254,3 +264,289 @@ func TestLoad_PartialConfig(t *testing.T) {
 	}
 
 }
+
+func TestLoad_ScanConfig(t *testing.T) {
+	tmpDir := setupTempHome(t)
+
+	configDir := filepath.Join(tmpDir, ".stamp")
+	os.MkdirAll(configDir, 0o755)
+
+	content := `scan:
+  P:
+    recursive: true
+    max_depth: 2
+    dirs: ["~/Archive"]
+    ignore: ["node_modules"]
+  "*":
+    dirs: ["Old"]
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	scan := cfg.ScanFor("p")
+	if !scan.Recursive || scan.MaxDepth != 2 {
+		t.Errorf("ScanFor(p) = %+v, want recursive with depth 2", scan)
+	}
+	if len(scan.Dirs) != 1 || scan.Dirs[0] != filepath.Join(tmpDir, "Archive") {
+		t.Errorf("ScanFor(p).Dirs = %v, want expanded ~/Archive", scan.Dirs)
+	}
+
+	fallback := cfg.ScanFor("jin")
+	if len(fallback.Dirs) != 1 || fallback.Dirs[0] != "Old" {
+		t.Errorf("ScanFor(jin) = %+v, want fallback entry", fallback)
+	}
+}
+
+func TestLoad_TitleConfig(t *testing.T) {
+	tmpDir := setupTempHome(t)
+
+	configDir := filepath.Join(tmpDir, ".stamp")
+	os.MkdirAll(configDir, 0o755)
+
+	content := `title:
+  style: kebab
+  transliterate: true
+  max_length: 40
+  target: windows
+  replacement: "_"
+`
+	if err := os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := TitleConfig{Style: "kebab", Transliterate: true, MaxLength: 40, Target: "windows", Replacement: "_"}
+	if cfg.Title != want {
+		t.Errorf("Title = %+v, want %+v", cfg.Title, want)
+	}
+}
+
+func TestLoadFrom_LocalConfig(t *testing.T) {
+	tmpDir := setupTempHome(t)
+
+	configDir := filepath.Join(tmpDir, ".stamp")
+	os.MkdirAll(configDir, 0o755)
+	globalFile := filepath.Join(configDir, "config.yaml")
+	global := `timezone: Asia/Tokyo
+title:
+  style: kebab
+types:
+  interview:
+    kind: seq
+    prefix: INT
+    width: 4
+`
+	if err := os.WriteFile(globalFile, []byte(global), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	workspace := filepath.Join(tmpDir, "work")
+	research := filepath.Join(workspace, "research")
+	nested := filepath.Join(research, "2025")
+	os.MkdirAll(nested, 0o755)
+
+	outer := `seq:
+  prefix: ws
+templates:
+  project: templates/project.md
+`
+	inner := `seq:
+  prefix: jin
+  width: 3
+title:
+  max_length: 40
+types:
+  interview: "seq prefix INT width 2"
+`
+	if err := os.WriteFile(filepath.Join(workspace, LocalConfigName), []byte(outer), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	innerFile := filepath.Join(research, LocalConfigName)
+	if err := os.WriteFile(innerFile, []byte(inner), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFrom(nested)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	if cfg.Seq.Prefix != "jin" || cfg.Seq.Width != 3 {
+		t.Errorf("Seq = %+v, want jin/3 from the closest file", cfg.Seq)
+	}
+	if cfg.Timezone != "Asia/Tokyo" {
+		t.Errorf("Timezone = %q, want global value", cfg.Timezone)
+	}
+	if cfg.Title.Style != "kebab" || cfg.Title.MaxLength != 40 {
+		t.Errorf("Title = %+v, want fields merged across files", cfg.Title)
+	}
+	if got := cfg.Types["interview"]; got.Width != 2 {
+		t.Errorf("Types[interview].Width = %d, want local override", got.Width)
+	}
+	if want := filepath.Join(workspace, "templates", "project.md"); cfg.Templates["project"] != want {
+		t.Errorf("Templates[project] = %q, want %q", cfg.Templates["project"], want)
+	}
+
+	if files := cfg.Files(); len(files) != 3 || files[0] != globalFile || files[2] != innerFile {
+		t.Errorf("Files() = %v", files)
+	}
+
+	origins := map[string]Source{
+		"seq.prefix":           {File: innerFile, Line: 2},
+		"timezone":             {File: globalFile, Line: 1},
+		"title.style":          {File: globalFile, Line: 3},
+		"title.max_length":     {File: innerFile, Line: 5},
+		"types.interview.kind": {File: innerFile, Line: 7},
+		"week_start":           {},
+	}
+	for key, want := range origins {
+		if got := cfg.Origin(key); got != want {
+			t.Errorf("Origin(%s) = %v, want %v", key, got, want)
+		}
+	}
+
+	entries, err := cfg.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	found := false
+	for _, entry := range entries {
+		if entry.Key == "seq.width" {
+			found = true
+			if entry.Value != "3" || entry.Origin.File != innerFile {
+				t.Errorf("seq.width entry = %+v", entry)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Entries() missing seq.width: %+v", entries)
+	}
+}
+
+func TestLoadFrom_GlobalOnlyKeys(t *testing.T) {
+	tmpDir := setupTempHome(t)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	globalFile := filepath.Join(tmpDir, ".stamp", "config.yaml")
+	os.MkdirAll(filepath.Dir(globalFile), 0o755)
+	global := `counter_server:
+  url: http://stamp.lan:7777
+  token: s3cret
+`
+	if err := os.WriteFile(globalFile, []byte(global), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := filepath.Join(tmpDir, "clone")
+	os.MkdirAll(repo, 0o755)
+	local := `timezone: UTC
+counter_server:
+  url: http://127.0.0.1:7991
+counter_file: /etc/passwd
+counter_backend: sqlite
+`
+	localFile := filepath.Join(repo, LocalConfigName)
+	if err := os.WriteFile(localFile, []byte(local), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFrom(repo)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if cfg.CounterServer.URL != "http://stamp.lan:7777" || cfg.Origin("counter_server.url").File != globalFile {
+		t.Errorf("CounterServer = %+v from %v, want the global server", cfg.CounterServer, cfg.Origin("counter_server.url"))
+	}
+	if cfg.Timezone != "UTC" {
+		t.Errorf("Timezone = %q, want the local value", cfg.Timezone)
+	}
+	if cfg.CounterFile != Default().CounterFile || cfg.CounterBackend != "" {
+		t.Errorf("counter_file = %q, counter_backend = %q, want defaults", cfg.CounterFile, cfg.CounterBackend)
+	}
+	ignored := cfg.Ignored()
+	if len(ignored) != 3 || ignored[0].Key != "counter_server" || ignored[0].File != localFile || ignored[0].Line != 2 || ignored[2].Key != "counter_backend" {
+		t.Errorf("Ignored() = %v", ignored)
+	}
+
+	problems, err := CheckLocal(localFile)
+	if err != nil {
+		t.Fatalf("CheckLocal() error = %v", err)
+	}
+	if len(problems) != 3 || problems[1].Key != "counter_file" {
+		t.Errorf("CheckLocal() = %v", problems)
+	}
+	if problems, _ := Check(globalFile); len(problems) != 0 {
+		t.Errorf("Check(global) = %v", problems)
+	}
+}
+
+func TestLoadFrom_LocalTemplatesStayInside(t *testing.T) {
+	tmpDir := setupTempHome(t)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	secret := filepath.Join(tmpDir, ".ssh", "id_ed25519")
+	os.MkdirAll(filepath.Dir(secret), 0o700)
+	os.WriteFile(secret, []byte("key"), 0o600)
+
+	repo := filepath.Join(tmpDir, "clone")
+	os.MkdirAll(filepath.Join(repo, "tmpl"), 0o755)
+	if err := os.Symlink(secret, filepath.Join(repo, "tmpl", "link.md")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	local := `templates:
+  daily: ~/.ssh/id_ed25519
+  weekly: ../notes/weekly.md
+  monthly: tmpl/link.md
+  meeting: tmpl/meeting.md
+`
+	localFile := filepath.Join(repo, LocalConfigName)
+	if err := os.WriteFile(localFile, []byte(local), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFrom(repo)
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+	if len(cfg.Templates) != 1 || cfg.Templates["meeting"] != filepath.Join(repo, "tmpl", "meeting.md") {
+		t.Errorf("Templates = %v, want only the template inside the repository", cfg.Templates)
+	}
+	ignored := cfg.Ignored()
+	if len(ignored) != 3 || ignored[0].Key != "templates.daily" || ignored[0].Line != 2 || ignored[2].Key != "templates.monthly" {
+		t.Errorf("Ignored() = %v", ignored)
+	}
+
+	problems, err := CheckLocal(localFile)
+	if err != nil {
+		t.Fatalf("CheckLocal() error = %v", err)
+	}
+	if len(problems) != 3 || problems[1].Key != "templates.weekly" {
+		t.Errorf("CheckLocal() = %v", problems)
+	}
+}
+
+func TestEntries_MasksSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.CounterServer = CounterServerConfig{URL: "http://stamp.lan:7777", Token: "s3cret"}
+
+	entries, err := cfg.Entries()
+	if err != nil {
+		t.Fatalf("Entries() error = %v", err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Value, "s3cret") {
+			t.Errorf("Entries() leaks the token in %s", entry.Key)
+		}
+		if entry.Key == "counter_server.url" && entry.Value != "http://stamp.lan:7777" {
+			t.Errorf("counter_server.url = %q", entry.Value)
+		}
+	}
+}
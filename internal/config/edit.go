@@ -0,0 +1,360 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is a configuration file edited in place. Set and Unset rewrite only
+// the lines of the top-level entry they touch, so comments, blank lines and
+// keys that were never set stay as the user wrote them.
+type File struct {
+	path  string
+	lines []string // with their line endings
+	doc   yaml.Node
+}
+
+// OpenFile reads the configuration file at path for editing. A missing file
+// is treated as empty.
+func OpenFile(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	f := &File{path: path}
+	if err := f.load(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+func (f *File) load(data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) > 0 && doc.Content[0].Kind != yaml.MappingNode {
+		return errors.New("configuration must be a mapping of keys to values")
+	}
+	f.doc = doc
+	f.lines = strings.SplitAfter(string(data), "\n")
+	if f.lines[len(f.lines)-1] == "" {
+		f.lines = f.lines[:len(f.lines)-1]
+	}
+	return nil
+}
+
+// Bytes returns the file's current contents.
+func (f *File) Bytes() []byte {
+	return []byte(strings.Join(f.lines, ""))
+}
+
+// Save writes the file, creating its directory if needed.
+func (f *File) Save() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, f.Bytes(), 0o600)
+}
+
+// Config returns the file's settings over the defaults, with origins, so
+// Validate can report where a value was set.
+func (f *File) Config() (*Config, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	cfg := Default()
+	cfg.origins = map[string]Source{}
+	if err := cfg.mergeData(f.path, f.Bytes(), home, ""); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Set assigns value to the dotted key (e.g. "title.style" or
+// "types.meeting"). The value is parsed as YAML, so lists such as
+// "[Archive, Old]" and type shorthands work as they do in the file.
+func (f *File) Set(key, value string) error {
+	path, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	var parsed yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &parsed); err == nil && len(parsed.Content) == 1 {
+		valueNode = parsed.Content[0]
+	}
+	if err := checkKey(path, key, valueNode); err != nil {
+		return err
+	}
+
+	root, err := f.root()
+	if err != nil {
+		return err
+	}
+	index := entryIndex(root, path[0])
+	if index < 0 {
+		// New keys go after the last entry, ahead of any closing comments.
+		at := len(f.lines)
+		if n := len(root.Content) / 2; n > 0 {
+			_, _, at = f.entryLines(root, n-1)
+		}
+		if at > 0 && !strings.HasSuffix(f.lines[at-1], "\n") {
+			f.lines[at-1] += "\n"
+		}
+		entry := &yaml.Node{Kind: yaml.MappingNode}
+		setNode(entry, path, valueNode)
+		return f.splice(at, at, entry.Content[0], entry.Content[1])
+	}
+
+	start, _, end := f.entryLines(root, index)
+	setNode(root, path, valueNode)
+	return f.splice(start, end, root.Content[2*index], root.Content[2*index+1])
+}
+
+// Unset removes the dotted key, restoring its default. Sections left empty
+// are removed with it.
+func (f *File) Unset(key string) error {
+	path, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+
+	root, err := f.root()
+	if err != nil {
+		return err
+	}
+	index := entryIndex(root, path[0])
+	if index < 0 {
+		return fmt.Errorf("config key %q is not set", key)
+	}
+	start, head, end := f.entryLines(root, index)
+	entries := len(root.Content)
+	if !deleteNode(root, path) {
+		return fmt.Errorf("config key %q is not set", key)
+	}
+	if len(root.Content) == entries {
+		return f.splice(start, end, root.Content[2*index], root.Content[2*index+1])
+	}
+
+	// The whole entry went: drop its comment too, and one of the blank lines
+	// around it.
+	if end < len(f.lines) && isBlank(f.lines[end]) && (head == 0 || isBlank(f.lines[head-1])) {
+		end++
+	}
+	return f.splice(head, end, nil, nil)
+}
+
+func (f *File) root() (*yaml.Node, error) {
+	if len(f.doc.Content) == 0 {
+		f.doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := f.doc.Content[0]
+	if root.Style&yaml.FlowStyle == 0 {
+		return root, nil
+	}
+
+	// Entries of a {flow: mapping} share lines, so write it out as a block.
+	root.Style = 0
+	data, err := yaml.Marshal(&f.doc)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.load(data); err != nil {
+		return nil, err
+	}
+	return f.doc.Content[0], nil
+}
+
+// entryLines locates the top-level entry at index as 0-based line indexes:
+// the comment lines directly above it start at head, the key at start, and
+// the entry ends before end. Trailing blank lines and unindented comments
+// belong to the gap, not the entry.
+func (f *File) entryLines(root *yaml.Node, index int) (start, head, end int) {
+	key := root.Content[2*index]
+	start = key.Line - 1
+	head = f.commentAbove(start)
+
+	end = len(f.lines)
+	if next := 2 * (index + 1); next < len(root.Content) {
+		end = f.commentAbove(root.Content[next].Line - 1)
+	}
+	for end > start+1 {
+		line := f.lines[end-1]
+		trimmed := strings.TrimLeft(line, " \t")
+		if !isBlank(line) && !(strings.HasPrefix(trimmed, "#") && len(line)-len(trimmed) < key.Column) {
+			break
+		}
+		end--
+	}
+	return start, head, end
+}
+
+// commentAbove returns the first of the comment lines directly above line.
+func (f *File) commentAbove(line int) int {
+	for line > 0 && strings.HasPrefix(strings.TrimSpace(f.lines[line-1]), "#") {
+		line--
+	}
+	return line
+}
+
+// splice replaces lines [start, end) with the entry key: value, or removes
+// them when key is nil, and reloads the file.
+func (f *File) splice(start, end int, key, value *yaml.Node) error {
+	var text string
+	if key != nil {
+		// Comments above and below the entry are kept in place.
+		k, v := *key, *value
+		k.HeadComment, k.FootComment, v.FootComment = "", "", ""
+		var b bytes.Buffer
+		encoder := yaml.NewEncoder(&b)
+		encoder.SetIndent(f.indent())
+		if err := encoder.Encode(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{&k, &v}}); err != nil {
+			return err
+		}
+		if err := encoder.Close(); err != nil {
+			return err
+		}
+		text = b.String()
+	}
+
+	lines := append([]string{}, f.lines[:start]...)
+	lines = append(lines, text)
+	lines = append(lines, f.lines[end:]...)
+	return f.load([]byte(strings.Join(lines, "")))
+}
+
+// indent returns the indentation of the file's first nested mapping, or 2.
+func (f *File) indent() int {
+	if len(f.doc.Content) == 0 {
+		return 2
+	}
+	root := f.doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if value.Kind == yaml.MappingNode && value.Style&yaml.FlowStyle == 0 && len(value.Content) > 0 {
+			if n := value.Content[0].Column - key.Column; n > 0 {
+				return n
+			}
+		}
+	}
+	return 2
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func entryIndex(root *yaml.Node, key string) int {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			return i / 2
+		}
+	}
+	return -1
+}
+
+func splitKey(key string) ([]string, error) {
+	path := strings.Split(key, ".")
+	for _, part := range path {
+		if part == "" {
+			return nil, fmt.Errorf("invalid config key %q", key)
+		}
+	}
+	return path, nil
+}
+
+// checkKey decodes key: value on its own over the defaults, rejecting unknown
+// keys and values of the wrong type.
+func checkKey(path []string, key string, value *yaml.Node) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	setNode(root, path, value)
+	data, err := yaml.Marshal(root)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(Default()); err != nil {
+		if strings.Contains(err.Error(), "not found in type") {
+			return fmt.Errorf("unknown config key %q", key)
+		}
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return nil
+}
+
+func child(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setNode(node *yaml.Node, path []string, value *yaml.Node) {
+	for i, part := range path {
+		next := child(node, part)
+		if i == len(path)-1 {
+			if next != nil {
+				// Keep the comment on the line being replaced.
+				comment := next.LineComment
+				*next = *value
+				if next.LineComment == "" {
+					next.LineComment = comment
+				}
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, value)
+			}
+			return
+		}
+		if next == nil || next.Kind != yaml.MappingNode {
+			mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if next != nil {
+				*next = *mapping
+				mapping = next
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, mapping)
+			}
+			next = mapping
+		}
+		node = next
+	}
+}
+
+// deleteNode removes path from node, along with mappings it leaves empty.
+func deleteNode(node *yaml.Node, path []string) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) > 1 {
+			value := node.Content[i+1]
+			if !deleteNode(value, path[1:]) {
+				return false
+			}
+			if len(value.Content) > 0 {
+				return true
+			}
+		}
+		node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// userConfig is a hand-written global file with comments, blank lines and
+// four-space indentation.
+const userConfig = `# stamp settings
+timezone: Europe/Paris # office
+
+# Titles
+title:
+    style: kebab # for the wiki
+    # max_length: 60
+    transliterate: true
+
+types:
+    meeting: "seq prefix MTG"
+
+# Trailing notes
+`
+
+func openTestFile(t *testing.T, content string) *File {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	return f
+}
+
+func TestFile_SetUnset(t *testing.T) {
+	f := openTestFile(t, "")
+
+	steps := []struct {
+		key, value string
+	}{
+		{"timezone", "Asia/Tokyo"},
+		{"title.style", "kebab"},
+		{"title.max_length", "40"},
+		{"types.interview", "seq prefix INT width 3"},
+		{"scan.P.dirs", "[Archive, Old]"},
+		{"always_extension", "true"},
+	}
+	for _, step := range steps {
+		if err := f.Set(step.key, step.value); err != nil {
+			t.Fatalf("Set(%s, %s) error = %v", step.key, step.value, err)
+		}
+	}
+
+	cfg, err := f.Config()
+	if err != nil {
+		t.Fatalf("Config() error = %v", err)
+	}
+	if cfg.Timezone != "Asia/Tokyo" || !cfg.AlwaysExtension {
+		t.Errorf("scalars not set: %+v", cfg)
+	}
+	if cfg.Title.Style != "kebab" || cfg.Title.MaxLength != 40 {
+		t.Errorf("Title = %+v", cfg.Title)
+	}
+	if got := cfg.Types["interview"]; got.Kind != KindSeq || got.Prefix != "INT" || got.Width != 3 {
+		t.Errorf("Types[interview] = %+v", got)
+	}
+	if dirs := cfg.Scan["P"].Dirs; len(dirs) != 2 || dirs[1] != "Old" {
+		t.Errorf("Scan[P].Dirs = %v", dirs)
+	}
+	if origin := cfg.Origin("title.max_length"); origin.Line != 4 {
+		t.Errorf("Origin(title.max_length) = %v, want line 4", origin)
+	}
+
+	before := string(f.Bytes())
+	if err := f.Set("bogus", "1"); err == nil {
+		t.Error("Set(bogus) error = nil, want unknown key")
+	}
+	if err := f.Set("title.colour", "red"); err == nil {
+		t.Error("Set(title.colour) error = nil, want unknown key")
+	}
+	if err := f.Set("title.max_length", "lots"); err == nil {
+		t.Error("Set(title.max_length, lots) error = nil, want invalid value")
+	}
+	if got := string(f.Bytes()); got != before {
+		t.Errorf("failed Set changed the file:\n%s", got)
+	}
+
+	if err := f.Unset("types.interview"); err != nil {
+		t.Fatalf("Unset(types.interview) error = %v", err)
+	}
+	if err := f.Unset("seq.prefix"); err == nil {
+		t.Error("Unset of a key that is not set error = nil")
+	}
+	if strings.Contains(string(f.Bytes()), "types") {
+		t.Errorf("empty types section left behind:\n%s", f.Bytes())
+	}
+}
+
+func TestFile_WritesOnlySetKeys(t *testing.T) {
+	f := openTestFile(t, "")
+	if err := f.Set("timezone", "Asia/Tokyo"); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(f.Bytes()); got != "timezone: Asia/Tokyo\n" {
+		t.Errorf("Bytes() = %q, want only the key that was set", got)
+	}
+
+	if err := f.Unset("timezone"); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(f.Bytes()); got != "" {
+		t.Errorf("Bytes() = %q after unset, want empty", got)
+	}
+	if err := f.Unset("counter_file"); err == nil {
+		t.Error("Unset(counter_file) error = nil, want not set")
+	}
+}
+
+func TestFile_SetUnsetRoundTrip(t *testing.T) {
+	tests := []struct {
+		key, value string
+	}{
+		{"week_start", "sunday"},
+		{"counter_backend", "sqlite"},
+		{"title.max_length", "40"},
+		{"types.interview", "seq prefix INT width 3"},
+		{"seq.prefix", "S"},
+		{"scan.P.dirs", "[Archive, Old]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			f := openTestFile(t, userConfig)
+			if err := f.Set(tt.key, tt.value); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if err := f.Unset(tt.key); err != nil {
+				t.Fatalf("Unset() error = %v", err)
+			}
+			if got := string(f.Bytes()); got != userConfig {
+				t.Errorf("set and unset of %s changed the file:\n%s", tt.key, got)
+			}
+		})
+	}
+}
+
+func TestFile_KeepsComments(t *testing.T) {
+	f := openTestFile(t, userConfig)
+	if err := f.Set("timezone", "Asia/Tokyo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Set("title.style", "snake"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.NewReplacer(
+		"timezone: Europe/Paris", "timezone: Asia/Tokyo",
+		"style: kebab", "style: snake",
+	).Replace(userConfig)
+	if got := string(f.Bytes()); got != want {
+		t.Errorf("Bytes() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The comment above a key goes with it.
+	if err := f.Unset("timezone"); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(f.Bytes()); strings.Contains(got, "timezone") || !strings.HasPrefix(got, "# Titles\ntitle:\n") {
+		t.Errorf("Unset(timezone) =\n%s", got)
+	}
+}
+
+func TestFile_Save(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stamp", "config.yaml")
+	f, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("OpenFile() of a missing file error = %v", err)
+	}
+	if err := f.Set("week_start", "sunday"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "week_start: sunday\n" {
+		t.Errorf("saved %q", data)
+	}
+}
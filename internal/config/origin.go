@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source records where a configuration key was set: a file and line, or an
+// environment variable such as "$STAMP_TZ". The zero value stands for the
+// built-in default.
+type Source struct {
+	File string
+	Line int
+}
+
+func (s Source) String() string {
+	if s.File == "" {
+		return "default"
+	}
+	if s.Line == 0 {
+		// Environment variables have no line.
+		return s.File
+	}
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
+
+// Entry is a single effective configuration value.
+type Entry struct {
+	Key    string
+	Value  string
+	Origin Source
+}
+
+// Files returns the configuration files that were merged, in the order they
+// were applied.
+func (c *Config) Files() []string {
+	return append([]string(nil), c.files...)
+}
+
+// Origin reports where key (in dotted form, e.g. "seq.prefix") was set. Keys
+// inside a section set as a whole report the section's origin.
+func (c *Config) Origin(key string) Source {
+	for {
+		if source, ok := c.origins[key]; ok {
+			return source
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return Source{}
+		}
+		key = key[:i]
+	}
+}
+
+// secretKeys are masked by Entries so that `stamp config show` can be shared.
+var secretKeys = map[string]bool{"counter_server.token": true}
+
+// Entries flattens the effective configuration into dotted keys in file
+// order, each with its origin. Secrets such as counter_server.token are
+// masked.
+func (c *Config) Entries() ([]Entry, error) {
+	var doc yaml.Node
+	if err := doc.Encode(c); err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	var walk func(node *yaml.Node, prefix string)
+	walk = func(node *yaml.Node, prefix string) {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			path := key.Value
+			if prefix != "" {
+				path = prefix + "." + key.Value
+			}
+			if value.Kind == yaml.MappingNode && len(value.Content) > 0 {
+				walk(value, path)
+				continue
+			}
+			entry := Entry{Key: path, Value: nodeString(value), Origin: c.Origin(path)}
+			if secretKeys[path] && entry.Value != "" {
+				entry.Value = "********"
+			}
+			entries = append(entries, entry)
+		}
+	}
+	walk(&doc, "")
+	return entries, nil
+}
+
+// nodeString renders a value node on one line, using flow style for
+// collections.
+func nodeString(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode {
+		return node.Value
+	}
+	node.Style = yaml.FlowStyle
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Environment variables that override configuration.
+const (
+	EnvConfig      = "STAMP_CONFIG"       // global config file
+	EnvCounterFile = "STAMP_COUNTER_FILE" // counter_file
+	EnvTimezone    = "STAMP_TZ"           // timezone
+	EnvWeekStart   = "STAMP_WEEK_START"   // week_start
+)
+
+// Path returns the location of the global configuration file: $STAMP_CONFIG,
+// else $XDG_CONFIG_HOME/stamp/config.yaml when XDG_CONFIG_HOME is set, else
+// ~/.stamp/config.yaml.
+func Path() (string, error) {
+	if path := os.Getenv(EnvConfig); path != "" {
+		return filepath.Abs(path)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir(home), "config.yaml"), nil
+}
+
+func legacyDir(home string) string {
+	return filepath.Join(home, ".stamp")
+}
+
+func configDir(home string) string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "stamp")
+	}
+	return legacyDir(home)
+}
+
+// stateDir holds counters and other data stamp writes as it runs.
+func stateDir(home string) string {
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "stamp")
+	}
+	return legacyDir(home)
+}
+
+// applyEnv applies STAMP_* overrides, recording them as the value's origin.
+func (c *Config) applyEnv(home string) {
+	overrides := []struct {
+		env, key string
+		field    *string
+	}{
+		{EnvCounterFile, "counter_file", &c.CounterFile},
+		{EnvTimezone, "timezone", &c.Timezone},
+		{EnvWeekStart, "week_start", &c.WeekStart},
+	}
+	for _, o := range overrides {
+		value := os.Getenv(o.env)
+		if value == "" {
+			continue
+		}
+		if o.key == "counter_file" {
+			value = resolvePath(value, home, "")
+		}
+		*o.field = value
+		c.origins[o.key] = Source{File: "$" + o.env}
+	}
+}
+
+// Migrate copies config.yaml and the counter files from the legacy ~/.stamp
+// directory to the locations XDG_CONFIG_HOME and XDG_STATE_HOME select, for
+// stamp config migrate. Files already present there are left alone and the
+// originals are kept, so pointing XDG_STATE_HOME at a temporary directory
+// never takes the user's counters with it. It returns a note for each file
+// copied. Counters are only copied when counter_file is not configured.
+func Migrate() ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	legacy := legacyDir(home)
+
+	var notes []string
+	// copyGroup copies the files of names together: the later ones, such as
+	// SQLite's -wal and -shm files, only belong next to the first.
+	copyGroup := func(dir string, names ...string) error {
+		if dir == legacy {
+			return nil
+		}
+		for i, name := range names {
+			from, to := filepath.Join(legacy, name), filepath.Join(dir, name)
+			copied, err := copyIfAbsent(from, to)
+			if err != nil {
+				return err
+			}
+			if copied {
+				notes = append(notes, fmt.Sprintf("copied %s to %s", from, to))
+			} else if i == 0 {
+				return nil
+			}
+		}
+		return nil
+	}
+
+	if os.Getenv(EnvConfig) == "" {
+		if err := copyGroup(configDir(home), "config.yaml"); err != nil {
+			return notes, err
+		}
+	}
+
+	// A counter_file in the (possibly just copied) config keeps its counters
+	// where they are.
+	path, err := Path()
+	if err != nil {
+		return notes, err
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return notes, err
+	}
+	if _, set := cfg.origins["counter_file"]; set || os.Getenv(EnvCounterFile) != "" {
+		return notes, nil
+	}
+	// The counter file, its SQLite twin with its journal, and the history log
+	// and archive the counter package keeps next to it.
+	groups := [][]string{
+		{"counters.json"},
+		{"counters.db", "counters.db-wal", "counters.db-shm"},
+		{"counters.history.jsonl"},
+		{"counters.archive.json"},
+	}
+	for _, group := range groups {
+		if err := copyGroup(stateDir(home), group...); err != nil {
+			return notes, err
+		}
+	}
+	return notes, nil
+}
+
+// copyIfAbsent copies from to to unless from is missing or to already exists.
+func copyIfAbsent(from, to string) (bool, error) {
+	if _, err := os.Stat(from); err != nil {
+		return false, nil
+	}
+	if _, err := os.Stat(to); err == nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return false, err
+	}
+	if err := copyFile(from, to); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			// Another invocation copied it first.
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	return dst.Close()
+}
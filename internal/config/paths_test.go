@@ -0,0 +1,155 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	home := setupTempHome(t)
+
+	if got, _ := Path(); got != filepath.Join(home, ".stamp", "config.yaml") {
+		t.Errorf("Path() = %s, want legacy location", got)
+	}
+
+	xdg := filepath.Join(home, "xdg")
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	if got, _ := Path(); got != filepath.Join(xdg, "stamp", "config.yaml") {
+		t.Errorf("Path() with XDG_CONFIG_HOME = %s", got)
+	}
+
+	explicit := filepath.Join(home, "ci", "stamp.yaml")
+	t.Setenv(EnvConfig, explicit)
+	if got, _ := Path(); got != explicit {
+		t.Errorf("Path() with %s = %s, want %s", EnvConfig, got, explicit)
+	}
+}
+
+func TestDefault_XDGStateHome(t *testing.T) {
+	home := setupTempHome(t)
+	state := filepath.Join(home, "state")
+	t.Setenv("XDG_STATE_HOME", state)
+
+	if got := Default().CounterFile; got != filepath.Join(state, "stamp", "counters.json") {
+		t.Errorf("CounterFile = %s, want under XDG_STATE_HOME", got)
+	}
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	home := setupTempHome(t)
+
+	configFile := filepath.Join(home, "custom.yaml")
+	os.WriteFile(configFile, []byte("timezone: Asia/Tokyo\nweek_start: sunday\n"), 0o600)
+
+	t.Setenv(EnvConfig, configFile)
+	t.Setenv(EnvTimezone, "UTC")
+	t.Setenv(EnvCounterFile, "~/ci/counters.json")
+
+	cfg, err := LoadFrom("")
+	if err != nil {
+		t.Fatalf("LoadFrom() error = %v", err)
+	}
+
+	if cfg.Timezone != "UTC" {
+		t.Errorf("Timezone = %q, want %s override", cfg.Timezone, EnvTimezone)
+	}
+	if cfg.WeekStart != "sunday" {
+		t.Errorf("WeekStart = %q, want value from %s file", cfg.WeekStart, EnvConfig)
+	}
+	if want := filepath.Join(home, "ci", "counters.json"); cfg.CounterFile != want {
+		t.Errorf("CounterFile = %q, want %q", cfg.CounterFile, want)
+	}
+	if got := cfg.Origin("timezone").String(); got != "$"+EnvTimezone {
+		t.Errorf("Origin(timezone) = %s", got)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	home := setupTempHome(t)
+	legacy := filepath.Join(home, ".stamp")
+	os.MkdirAll(legacy, 0o755)
+	os.WriteFile(filepath.Join(legacy, "config.yaml"), []byte("timezone: Asia/Tokyo\n"), 0o600)
+	os.WriteFile(filepath.Join(legacy, "counters.json"), []byte(`{"analog":{"2025-11-12":3}}`), 0o600)
+	os.WriteFile(filepath.Join(legacy, "counters.history.jsonl"), []byte("{}\n"), 0o600)
+	os.WriteFile(filepath.Join(legacy, "counters.db"), []byte("db"), 0o600)
+	os.WriteFile(filepath.Join(legacy, "counters.db-wal"), []byte("wal"), 0o600)
+
+	// Without XDG variables nothing is copied.
+	if notes, err := Migrate(); err != nil || len(notes) != 0 {
+		t.Fatalf("Migrate() = %v, %v; want no-op", notes, err)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "cfg"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+
+	notes, err := Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(notes) != 5 {
+		t.Errorf("Migrate() notes = %v, want config, counters, database, journal and history copied", notes)
+	}
+
+	for _, name := range []string{"cfg/stamp/config.yaml", "state/stamp/counters.json", "state/stamp/counters.history.jsonl", "state/stamp/counters.db-wal"} {
+		if _, err := os.Stat(filepath.Join(home, filepath.FromSlash(name))); err != nil {
+			t.Errorf("%s not created: %v", name, err)
+		}
+	}
+	// The originals stay, so a temporary XDG_STATE_HOME cannot take them.
+	for _, name := range []string{"config.yaml", "counters.json", "counters.db-wal"} {
+		if _, err := os.Stat(filepath.Join(legacy, name)); err != nil {
+			t.Errorf("legacy %s removed: %v", name, err)
+		}
+	}
+
+	cfg, err := LoadFrom("")
+	if err != nil || cfg.Timezone != "Asia/Tokyo" {
+		t.Errorf("LoadFrom() after migration = %+v, %v", cfg, err)
+	}
+
+	// A second run finds nothing to do.
+	if notes, err := Migrate(); err != nil || len(notes) != 0 {
+		t.Errorf("second Migrate() = %v, %v; want no-op", notes, err)
+	}
+}
+
+func TestMigrate_KeepsConfiguredCounterFile(t *testing.T) {
+	home := setupTempHome(t)
+	legacy := filepath.Join(home, ".stamp")
+	os.MkdirAll(legacy, 0o755)
+	os.WriteFile(filepath.Join(legacy, "config.yaml"), []byte("counter_file: ~/.stamp/counters.json\n"), 0o600)
+	os.WriteFile(filepath.Join(legacy, "counters.json"), []byte(`{}`), 0o600)
+
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "cfg"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+
+	if _, err := Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "counters.json")); err != nil {
+		t.Errorf("counters.json removed despite explicit counter_file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, "state", "stamp", "counters.json")); !os.IsNotExist(err) {
+		t.Error("counters.json copied despite explicit counter_file")
+	}
+}
+
+func TestMigrate_KeepsJournalWithItsDatabase(t *testing.T) {
+	home := setupTempHome(t)
+	legacy := filepath.Join(home, ".stamp")
+	state := filepath.Join(home, "state", "stamp")
+	os.MkdirAll(legacy, 0o755)
+	os.MkdirAll(state, 0o755)
+	os.WriteFile(filepath.Join(legacy, "counters.db"), []byte("old"), 0o600)
+	os.WriteFile(filepath.Join(legacy, "counters.db-wal"), []byte("old wal"), 0o600)
+	os.WriteFile(filepath.Join(state, "counters.db"), []byte("new"), 0o600)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+
+	if _, err := Migrate(); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(state, "counters.db-wal")); !os.IsNotExist(err) {
+		t.Error("journal copied next to a different database")
+	}
+}
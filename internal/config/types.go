@@ -0,0 +1,132 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Note type kinds supported in the `types` section.
+const (
+	KindDate = "date"
+	KindSeq  = "seq"
+)
+
+// NoteType describes a user-defined note type declared under `types` in
+// config.yaml. It can be written in full:
+//
+//	meeting:
+//	  format: "YYYY-MM-DD-[M]HHmm"
+//	  description: Meeting notes
+//
+// or as a shorthand string, either a Moment.js style date format or a
+// sequential spec:
+//
+//	meeting: "YYYY-MM-DD-[M]HHmm"
+//	interview: seq prefix INT width 3
+type NoteType struct {
+	Kind        string `yaml:"kind,omitempty"`
+	Format      string `yaml:"format,omitempty"`
+	Prefix      string `yaml:"prefix,omitempty"`
+	Width       int    `yaml:"width,omitempty"`
+	Start       int    `yaml:"start,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	Extension   string `yaml:"extension,omitempty"`
+}
+
+// UnmarshalYAML accepts both the mapping and the shorthand string forms.
+func (t *NoteType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		parsed, err := parseNoteTypeShorthand(node.Value)
+		if err != nil {
+			// A TypeError lets decoding continue so every problem is reported.
+			return &yaml.TypeError{Errors: []string{fmt.Sprintf("line %d: %v", node.Line, err)}}
+		}
+		*t = parsed
+		return nil
+	}
+
+	type plain NoteType
+	var decoded plain
+	if err := node.Decode(&decoded); err != nil {
+		return err
+	}
+	*t = NoteType(decoded)
+	if t.Kind == "" {
+		if t.Format == "" && t.Prefix != "" {
+			t.Kind = KindSeq
+		} else {
+			t.Kind = KindDate
+		}
+	}
+	return nil
+}
+
+func parseNoteTypeShorthand(value string) (NoteType, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], KindSeq) {
+		return NoteType{Kind: KindDate, Format: value}, nil
+	}
+
+	t := NoteType{Kind: KindSeq}
+	rest := fields[1:]
+	if len(rest)%2 != 0 {
+		return t, fmt.Errorf("invalid seq shorthand %q: expected key/value pairs", value)
+	}
+	for i := 0; i < len(rest); i += 2 {
+		key, val := strings.ToLower(rest[i]), rest[i+1]
+		switch key {
+		case "prefix":
+			t.Prefix = val
+		case "width", "start":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return t, fmt.Errorf("invalid seq shorthand %q: %s must be a number", value, key)
+			}
+			if key == "width" {
+				t.Width = n
+			} else {
+				t.Start = n
+			}
+		case "ext", "extension":
+			t.Extension = val
+		default:
+			return t, fmt.Errorf("invalid seq shorthand %q: unknown key %q", value, rest[i])
+		}
+	}
+	return t, nil
+}
+
+// Validate reports whether the note type is usable.
+func (t NoteType) Validate() error {
+	switch t.Kind {
+	case KindDate, "":
+		if t.Format == "" {
+			return fmt.Errorf("date note types require a format")
+		}
+	case KindSeq:
+		if t.Prefix == "" {
+			return fmt.Errorf("seq note types require a prefix")
+		}
+		if t.Width < 0 || t.Start < 0 {
+			return fmt.Errorf("seq width and start must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown kind %q (want %q or %q)", t.Kind, KindDate, KindSeq)
+	}
+	return nil
+}
+
+// FileExtension returns the extension applied when extensions are enabled,
+// defaulting to ".md".
+func (t NoteType) FileExtension() string {
+	if t.Extension == "" {
+		return ".md"
+	}
+	if !strings.HasPrefix(t.Extension, ".") {
+		return "." + t.Extension
+	}
+	return t.Extension
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestNoteTypeUnmarshal(t *testing.T) {
+	input := `
+types:
+  meeting: "YYYY-MM-DD-[M]HHmm"
+  interview: seq prefix INT width 3
+  review:
+    format: "YYYY-[W]WW"
+    description: Weekly review
+    extension: txt
+  client:
+    prefix: CL
+    start: 10
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(input), &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	tests := map[string]NoteType{
+		"meeting":   {Kind: KindDate, Format: "YYYY-MM-DD-[M]HHmm"},
+		"interview": {Kind: KindSeq, Prefix: "INT", Width: 3},
+		"review":    {Kind: KindDate, Format: "YYYY-[W]WW", Description: "Weekly review", Extension: "txt"},
+		"client":    {Kind: KindSeq, Prefix: "CL", Start: 10},
+	}
+
+	for name, want := range tests {
+		got, ok := cfg.Types[name]
+		if !ok {
+			t.Errorf("type %q missing", name)
+			continue
+		}
+		if got != want {
+			t.Errorf("type %q = %+v, want %+v", name, got, want)
+		}
+		if err := got.Validate(); err != nil {
+			t.Errorf("type %q Validate() error = %v", name, err)
+		}
+	}
+
+	if ext := cfg.Types["review"].FileExtension(); ext != ".txt" {
+		t.Errorf("FileExtension() = %q, want .txt", ext)
+	}
+	if ext := cfg.Types["meeting"].FileExtension(); ext != ".md" {
+		t.Errorf("FileExtension() = %q, want .md", ext)
+	}
+}
+
+func TestNoteTypeInvalid(t *testing.T) {
+	var cfg Config
+	if err := yaml.Unmarshal([]byte("types:\n  broken: seq prefix\n"), &cfg); err == nil {
+		t.Error("expected error for incomplete seq shorthand")
+	}
+
+	invalid := []NoteType{
+		{Kind: KindDate},
+		{Kind: KindSeq},
+		{Kind: "weekly", Format: "YYYY"},
+	}
+	for _, nt := range invalid {
+		if err := nt.Validate(); err == nil {
+			t.Errorf("Validate(%+v) expected error", nt)
+		}
+	}
+}
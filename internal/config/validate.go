@@ -0,0 +1,193 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toto/stamp/internal/calendar"
+	"github.com/toto/stamp/internal/naming"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is a configuration error located in a file.
+type Problem struct {
+	File    string
+	Line    int
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	var b strings.Builder
+	if p.File != "" {
+		b.WriteString(p.File)
+		if p.Line > 0 {
+			fmt.Fprintf(&b, ":%d", p.Line)
+		}
+		b.WriteString(": ")
+	}
+	if p.Key != "" {
+		b.WriteString(p.Key + ": ")
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+var (
+	yamlLinePattern     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type`)
+)
+
+// Check validates a single configuration file, reporting syntax errors,
+// unknown keys and invalid values with their line numbers.
+func Check(path string) ([]Problem, error) {
+	return check(path, false)
+}
+
+// CheckLocal is Check for a .stamp.yaml, also reporting keys only the global
+// file can set and templates outside the file's directory.
+func CheckLocal(path string) ([]Problem, error) {
+	return check(path, true)
+}
+
+func check(path string, local bool) ([]Problem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Problem{yamlProblem(path, err.Error(), nil)}, nil
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	origins := map[string]Source{}
+	recordOrigins(doc.Content[0], "", path, origins)
+
+	var problems []Problem
+	if local {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, dropGlobalOnly(doc.Content[0], path)...)
+		problems = append(problems, dropOutsideTemplates(doc.Content[0], path, home, filepath.Dir(path))...)
+	}
+	cfg := Default()
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return append(problems, yamlProblem(path, err.Error(), origins)), nil
+		}
+		for _, msg := range typeErr.Errors {
+			problems = append(problems, yamlProblem(path, msg, origins))
+		}
+	}
+
+	cfg.origins = origins
+	problems = append(problems, cfg.Validate()...)
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems, nil
+}
+
+// yamlProblem converts a YAML decoder message such as "line 4: field foo not
+// found in type config.Config" into a Problem, naming the full key when the
+// line identifies it.
+func yamlProblem(path, msg string, origins map[string]Source) Problem {
+	problem := Problem{File: path, Message: msg}
+	m := yamlLinePattern.FindStringSubmatch(msg)
+	if m == nil {
+		return problem
+	}
+	problem.Line, _ = strconv.Atoi(m[1])
+	problem.Message = m[2]
+
+	field := ""
+	if match := unknownFieldPattern.FindStringSubmatch(m[2]); match != nil {
+		field = match[1]
+		problem.Message = "unknown key"
+		problem.Key = field
+	}
+	// Name the most specific key set on that line.
+	for key, source := range origins {
+		if source.Line != problem.Line {
+			continue
+		}
+		if field != "" && key != field && !strings.HasSuffix(key, "."+field) {
+			continue
+		}
+		if len(key) >= len(problem.Key) {
+			problem.Key = key
+		}
+	}
+	return problem
+}
+
+// Validate checks values that YAML decoding cannot, such as time zones and
+// note type definitions. Problems carry the location the key was set at.
+func (c *Config) Validate() []Problem {
+	var problems []Problem
+	report := func(key string, err error) {
+		source := c.Origin(key)
+		problems = append(problems, Problem{File: source.File, Line: source.Line, Key: key, Message: err.Error()})
+	}
+
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			report("timezone", fmt.Errorf("invalid timezone %q", c.Timezone))
+		}
+	}
+	if c.WeekStart != "" {
+		if _, err := calendar.ParseWeekday(c.WeekStart); err != nil {
+			report("week_start", err)
+		}
+	}
+	if _, err := ParseCounterBackend(c.CounterBackend); err != nil {
+		report("counter_backend", err)
+	}
+	if _, err := ParseAnalogMode(c.AnalogCounter); err != nil {
+		report("analog_counter", err)
+	}
+	if c.CounterServer.URL != "" {
+		if u, err := url.Parse(c.CounterServer.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			report("counter_server.url", fmt.Errorf("invalid URL %q (want http://host:port)", c.CounterServer.URL))
+		}
+	}
+	if c.CounterRetention != "" {
+		if _, err := ParseRetention(c.CounterRetention); err != nil {
+			report("counter_retention", err)
+		}
+	}
+	if err := naming.TitlePolicy(c.Title).Validate(); err != nil {
+		report("title", err)
+	}
+	if c.Seq.Width < 0 || c.Seq.Start < 0 {
+		report("seq", fmt.Errorf("width and start must not be negative"))
+	}
+
+	names := make([]string, 0, len(c.Types))
+	for name := range c.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := c.Types[name].Validate(); err != nil {
+			report("types."+name, err)
+		}
+	}
+	return problems
+}
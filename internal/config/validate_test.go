@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `timezone: Mars/Olympus
+week_start: funday
+bogus: 1
+title:
+  style: kebab
+  colour: red
+types:
+  interview: "seq prefix INT width x"
+  meeting: "YYYY-MM-DD"
+  broken:
+    kind: seq
+analog_counter: sometimes
+counter_retention: 3 months
+counter_backend: redis
+counter_server:
+  url: stamp.lan:7777
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := Check(path)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	want := []struct {
+		line int
+		key  string
+	}{
+		{1, "timezone"},
+		{2, "week_start"},
+		{3, "bogus"},
+		{6, "title.colour"},
+		{8, "types.interview"},
+		{10, "types.broken"},
+		{12, "analog_counter"},
+		{13, "counter_retention"},
+		{14, "counter_backend"},
+		{16, "counter_server.url"},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Check() = %d problems, want %d: %v", len(problems), len(want), problems)
+	}
+	for i, w := range want {
+		if problems[i].Line != w.line || problems[i].Key != w.key || problems[i].File != path {
+			t.Errorf("problem %d = %v, want line %d key %s", i, problems[i], w.line, w.key)
+		}
+	}
+}
+
+func TestCheck_ValidAndSyntaxError(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	os.WriteFile(valid, []byte("timezone: Asia/Tokyo\nseq:\n  prefix: jin\n"), 0o600)
+	if problems, err := Check(valid); err != nil || len(problems) != 0 {
+		t.Errorf("Check(valid) = %v, %v; want no problems", problems, err)
+	}
+
+	broken := filepath.Join(dir, "broken.yaml")
+	os.WriteFile(broken, []byte("timezone: UTC\n  week_start: [\n"), 0o600)
+	problems, err := Check(broken)
+	if err != nil {
+		t.Fatalf("Check(broken) error = %v", err)
+	}
+	if len(problems) != 1 || problems[0].Line == 0 {
+		t.Errorf("Check(broken) = %v, want one located syntax error", problems)
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseCounterBackend parses counter_backend into json, sqlite or memory; an
+// empty string means json.
+func ParseCounterBackend(s string) (string, error) {
+	switch backend := strings.ToLower(strings.TrimSpace(s)); backend {
+	case "":
+		return "json", nil
+	case "json", "sqlite", "memory":
+		return backend, nil
+	}
+	return "", fmt.Errorf("invalid counter backend %q (want json, sqlite or memory)", s)
+}
+
+// ParseAnalogMode parses analog_counter, or the --mode flag, into file, scan
+// or hybrid; an empty string means file.
+func ParseAnalogMode(s string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(s)); mode {
+	case "":
+		return "file", nil
+	case "file", "scan", "hybrid":
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid analog counter mode %q (want file, scan or hybrid)", s)
+}
+
+// ParseRetention parses an age such as "90d", "12w" or "2y", as used by
+// counter_retention, into days.
+func ParseRetention(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid age %q (want e.g. 90d, 12w or 1y)", s)
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid age %q (want e.g. 90d, 12w or 1y)", s)
+	}
+	switch s[len(s)-1] {
+	case 'd':
+		return n, nil
+	case 'w':
+		return n * 7, nil
+	case 'y':
+		return n * 365, nil
+	}
+	return 0, fmt.Errorf("invalid age %q (want e.g. 90d, 12w or 1y)", s)
+}
@@ -0,0 +1,63 @@
+package config
+
+import "testing"
+
+func TestParseCounterBackend(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "json", false},
+		{"SQLite", "sqlite", false},
+		{" memory ", "memory", false},
+		{"redis", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCounterBackend(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseCounterBackend(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseAnalogMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", "file", false},
+		{"Scan", "scan", false},
+		{"hybrid", "hybrid", false},
+		{"disk", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAnalogMode(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseAnalogMode(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"90d", 90, false},
+		{"12W", 84, false},
+		{"1y", 365, false},
+		{"6m", 0, true},
+		{"0d", 0, true},
+		{"d", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRetention(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRetention(%q) = %d, %v; want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
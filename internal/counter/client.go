@@ -0,0 +1,156 @@
+package counter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Client uses a `stamp serve` counter service. When the service cannot be
+// reached, analog numbers fall back to the local Manager and sequential
+// counters to the floor the caller found on disk, with a warning. Resets are
+// never applied locally in place of the service.
+type Client struct {
+	baseURL  string
+	token    string
+	http     *http.Client
+	local    *Manager
+	warnings io.Writer
+	warned   bool
+}
+
+var _ Service = (*Client)(nil)
+
+// unavailableError reports that the service could not answer.
+type unavailableError struct{ err error }
+
+func (e *unavailableError) Error() string { return e.err.Error() }
+func (e *unavailableError) Unwrap() error { return e.err }
+
+// NewClient returns a Client for the service at baseURL (e.g.
+// http://stamp.lan:7777), falling back to local.
+func NewClient(baseURL, token string, local *Manager) *Client {
+	return &Client{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		token:    token,
+		http:     &http.Client{Timeout: 5 * time.Second},
+		local:    local,
+		warnings: os.Stderr,
+	}
+}
+
+// NextAnalogAfter issues the next analog number from the service.
+func (c *Client) NextAnalogAfter(date string, floor int) (int, error) {
+	resp, err := c.call(http.MethodPost, "analog/"+url.PathEscape(date)+"/next", request{Floor: floor})
+	if c.fallback(err) {
+		return c.local.NextAnalogAfter(date, floor)
+	}
+	return resp.Value, err
+}
+
+// GetAnalogCounter returns the last analog number the service issued for date.
+func (c *Client) GetAnalogCounter(date string) (int, error) {
+	resp, err := c.call(http.MethodGet, "analog/"+url.PathEscape(date), request{})
+	if c.fallback(err) {
+		return c.local.GetAnalogCounter(date)
+	}
+	return resp.Value, err
+}
+
+// ResetAnalog resets the service's analog counter for date.
+func (c *Client) ResetAnalog(date string) error {
+	_, err := c.call(http.MethodPost, "analog/"+url.PathEscape(date)+"/reset", request{})
+	return err
+}
+
+// NextSeq issues the next value of a sequential counter from the service.
+func (c *Client) NextSeq(seq Seq, floor int) (int, error) {
+	resp, err := c.call(http.MethodPost, "seq/"+url.PathEscape(seq.Prefix)+"/next",
+		request{Floor: floor, Type: seq.Type, Width: seq.Width})
+	if c.fallback(err) {
+		return floor + 1, nil
+	}
+	return resp.Value, err
+}
+
+// GetSeq returns the last value the service issued for a sequential counter.
+func (c *Client) GetSeq(seq Seq) (int, error) {
+	resp, err := c.call(http.MethodGet, "seq/"+url.PathEscape(seq.Prefix), request{})
+	if c.fallback(err) {
+		return 0, nil
+	}
+	return resp.Value, err
+}
+
+// ResetSeq resets a sequential counter on the service.
+func (c *Client) ResetSeq(seq Seq) error {
+	_, err := c.call(http.MethodPost, "seq/"+url.PathEscape(seq.Prefix)+"/reset", request{Type: seq.Type})
+	return err
+}
+
+// fallback reports whether err means the service is unavailable, warning the
+// first time.
+func (c *Client) fallback(err error) bool {
+	var unavailable *unavailableError
+	if !errors.As(err, &unavailable) {
+		return false
+	}
+	if !c.warned {
+		fmt.Fprintf(c.warnings, "Warning: counter server %s unavailable (%v); using local counters\n", c.baseURL, err)
+		c.warned = true
+	}
+	return true
+}
+
+func (c *Client) call(method, path string, req request) (response, error) {
+	var body io.Reader
+	if method == http.MethodPost {
+		req.Host, _ = os.Hostname()
+		req.Dir, _ = os.Getwd()
+		data, err := json.Marshal(req)
+		if err != nil {
+			return response{}, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	httpReq, err := http.NewRequest(method, c.baseURL+"/v1/"+path, body)
+	if err != nil {
+		return response{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return response{}, &unavailableError{err}
+	}
+	defer httpResp.Body.Close()
+
+	var resp response
+	decodeErr := json.NewDecoder(httpResp.Body).Decode(&resp)
+	if httpResp.StatusCode == http.StatusOK && decodeErr == nil {
+		return resp, nil
+	}
+
+	message := resp.Error
+	if message == "" {
+		message = httpResp.Status
+	}
+	err = fmt.Errorf("counter server: %s", message)
+	// Server errors, and replies that are not from a counter service, make
+	// the service unavailable; other errors such as a bad token are reported.
+	if httpResp.StatusCode >= 500 || httpResp.StatusCode == http.StatusOK {
+		return response{}, &unavailableError{err}
+	}
+	return response{}, err
+}
@@ -1,148 +1,210 @@
 package counter
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
+	"time"
 )
 
-// Data stores analog counter information keyed by date.
-type Data struct {
-	Analog map[string]int `json:"analog"` // Date -> counter mapping
-}
-
-// Manager handles analog counter persistence and operations.
+// Manager handles analog counter operations on top of a Store. Issued
+// numbers and resets are also appended to a history log (see HistoryPath)
+// and old counters are archived next to the counter file (see ArchivePath).
 type Manager struct {
-	mu   sync.Mutex
-	file string
-	data *Data
+	store     Store
+	file      string // base path for the history and archive; empty disables them
+	retention int    // days; see SetRetention
 }
 
 // New creates a new counter manager that stores data in counterFile.
 func New(counterFile string) (*Manager, error) {
+	return Open(BackendJSON, counterFile)
+}
+
+// Open creates a counter manager using backend, storing data at path.
+func Open(backend Backend, path string) (*Manager, error) {
 	// Expand ~ to home directory
-	if strings.HasPrefix(counterFile, "~/") {
+	if strings.HasPrefix(path, "~/") {
 		home, err := os.UserHomeDir()
 		if err != nil {
 			return nil, err
 		}
-		counterFile = filepath.Join(home, counterFile[2:])
+		path = filepath.Join(home, path[2:])
 	}
 
-	m := &Manager{
-		file: counterFile,
-		data: &Data{
-			Analog: make(map[string]int),
-		},
+	store, err := OpenStore(backend, path)
+	if err != nil {
+		return nil, err
 	}
-
-	// Try to load existing data
-	if err := m.load(); err != nil {
-		// If file doesn't exist or is corrupted, start fresh
-		if !os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "Warning: Counter file corrupted, starting fresh: %v\n", err)
-		}
-		m.data = &Data{
-			Analog: make(map[string]int),
-		}
-		// Save initial data
-		if err := m.save(); err != nil {
-			return nil, err
-		}
+	if backend == BackendMemory {
+		path = ""
 	}
+	return NewWithStore(store, path), nil
+}
+
+// NewWithStore creates a counter manager on an open store. The history and
+// archive are kept next to file; an empty file disables both.
+func NewWithStore(store Store, file string) *Manager {
+	return &Manager{store: store, file: file}
+}
 
-	return m, nil
+// Path returns the counter file the history and archive are kept next to.
+func (m *Manager) Path() string {
+	return m.file
 }
 
-// load reads counter data from file
-func (m *Manager) load() error {
-	// Ensure directory exists
-	dir := filepath.Dir(m.file)
+// Close releases the store.
+func (m *Manager) Close() error {
+	return m.store.Close()
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return err
 	}
 
-	data, err := os.ReadFile(m.file)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
 	if err != nil {
 		return err
 	}
+	tmpName := tmp.Name()
 
-	var loaded Data
-	if err := json.Unmarshal(data, &loaded); err != nil {
+	cleanup := func(err error) error {
+		tmp.Close()
+		os.Remove(tmpName)
 		return err
 	}
-	if loaded.Analog == nil {
-		loaded.Analog = make(map[string]int)
-	}
 
-	m.data = &loaded
-	return nil
-}
-
-// save writes counter data to file
-func (m *Manager) save() error {
-	// Ensure directory exists
-	dir := filepath.Dir(m.file)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+	if _, err := tmp.Write(data); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
 		return err
 	}
 
-	data, err := json.MarshalIndent(m.data, "", "  ")
-	if err != nil {
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
 		return err
 	}
-
-	return os.WriteFile(m.file, data, 0o600)
+	return nil
 }
 
+// Mode selects where analog numbers come from.
+type Mode string
+
+const (
+	// ModeFile numbers notes from the counter file alone.
+	ModeFile Mode = "file"
+	// ModeScan numbers notes after the highest <date>-A<n> found on disk.
+	ModeScan Mode = "scan"
+	// ModeHybrid uses the larger of the stored and scanned values and
+	// records the result in the counter file.
+	ModeHybrid Mode = "hybrid"
+)
+
 // NextAnalog returns the next analog number for the given date and increments it
 func (m *Manager) NextAnalog(date string) (string, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	next, err := m.NextAnalogAfter(date, 0)
+	if err != nil {
+		return "", err
+	}
+	return FormatAnalog(date, next), nil
+}
 
-	// Get current counter for the date
-	current := m.data.Analog[date]
+// NextAnalogAfter issues the next analog number for date, at least floor + 1
+// so that numbers already taken by existing notes are never handed out again.
+func (m *Manager) NextAnalogAfter(date string, floor int) (int, error) {
+	return m.nextAnalog(date, floor, Event{})
+}
 
-	// Increment counter
-	m.data.Analog[date] = current + 1
+// nextAnalog implements NextAnalogAfter, returning the number issued and
+// recording the event with the working directory and host of origin when
+// they are set.
+func (m *Manager) nextAnalog(date string, floor int, origin Event) (int, error) {
+	var next int
+	err := m.store.Update(func(c Counters) error {
+		// Get current counter for the date
+		stored, err := m.stored(c, date)
+		if err != nil {
+			return err
+		}
 
-	// Save updated data
-	if err := m.save(); err != nil {
-		// Rollback on save failure
-		m.data.Analog[date] = current
-		return "", err
+		// Increment counter
+		next = max(stored, floor) + 1
+		c.Set(date, next)
+
+		if m.retention > 0 {
+			if _, err := m.prune(c, Cutoff(time.Now(), m.retention), false); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: could not prune old counters: %v\n", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	return fmt.Sprintf("%s-A%d", date, current+1), nil
+	origin.Type, origin.ID, origin.Date = "analog", FormatAnalog(date, next), date
+	m.logEvent(origin)
+	return next, nil
+}
+
+// FormatAnalog renders the analog ID for number n on date.
+func FormatAnalog(date string, n int) string {
+	return fmt.Sprintf("%s-A%d", date, n)
 }
 
 // CheckAnalog returns what the next analog number would be without incrementing
 func (m *Manager) CheckAnalog(date string) (string, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	current, err := m.GetAnalogCounter(date)
+	if err != nil {
+		return "", err
+	}
 
-	current := m.data.Analog[date]
-	return fmt.Sprintf("%s-A%d", date, current+1), nil
+	return FormatAnalog(date, current+1), nil
 }
 
 // ResetAnalog resets the counter for a specific date
 func (m *Manager) ResetAnalog(date string) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	return m.resetAnalog(date, Event{})
+}
 
-	delete(m.data.Analog, date)
-	return m.save()
+func (m *Manager) resetAnalog(date string, origin Event) error {
+	var previous int
+	err := m.store.Update(func(c Counters) error {
+		var err error
+		if previous, err = m.stored(c, date); err != nil {
+			return err
+		}
+		c.Delete(date)
+		return m.unarchive(date)
+	})
+	if err != nil {
+		return err
+	}
+	origin.Type, origin.Date, origin.Reset, origin.Previous = "analog", date, true, previous
+	m.logEvent(origin)
+	return nil
 }
 
 // GetAnalogCounter returns the current counter value for a date
 func (m *Manager) GetAnalogCounter(date string) (int, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	return m.data.Analog[date], nil
+	var current int
+	err := m.store.View(func(c Counters) error {
+		var err error
+		current, err = m.stored(c, date)
+		return err
+	})
+	return current, err
 }
 
 // All project counter methods have been removed; sequential IDs now scan the
@@ -1,8 +1,13 @@
 package counter
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -113,6 +118,38 @@ func TestManager_MultipleDates(t *testing.T) {
 	}
 }
 
+func TestManager_NextAnalogAfter(t *testing.T) {
+	manager, err := New(createTempCounterFile(t))
+	if err != nil {
+		t.Fatalf("Failed to create counter manager: %v", err)
+	}
+
+	date := "2025-11-12"
+	manager.NextAnalog(date)
+
+	// Files on disk are ahead of the stored counter.
+	result, err := manager.NextAnalogAfter(date, 5)
+	if err != nil {
+		t.Fatalf("NextAnalogAfter() error = %v", err)
+	}
+	if result != 6 {
+		t.Errorf("NextAnalogAfter(5) = %v, want 6", result)
+	}
+
+	// The stored counter is ahead of the files on disk.
+	result, err = manager.NextAnalogAfter(date, 2)
+	if err != nil {
+		t.Fatalf("NextAnalogAfter() error = %v", err)
+	}
+	if result != 7 {
+		t.Errorf("NextAnalogAfter(2) = %v, want 7", result)
+	}
+
+	if count, _ := manager.GetAnalogCounter(date); count != 7 {
+		t.Errorf("GetAnalogCounter() = %d, want 7", count)
+	}
+}
+
 func TestManager_Persistence(t *testing.T) {
 	counterFile := createTempCounterFile(t)
 
@@ -138,3 +175,157 @@ func TestManager_Persistence(t *testing.T) {
 		t.Errorf("NextAnalog() from second manager = %v, want 2025-11-12-A3", result)
 	}
 }
+
+func TestManager_CorruptedFileIsPreserved(t *testing.T) {
+	counterFile := createTempCounterFile(t)
+	if err := os.WriteFile(counterFile, []byte(`{"analog": {"2025-11-12": 4`), 0o600); err != nil {
+		t.Fatalf("failed to write corrupted file: %v", err)
+	}
+
+	manager, err := New(counterFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	result, err := manager.NextAnalog("2025-11-12")
+	if err != nil {
+		t.Fatalf("NextAnalog() error = %v", err)
+	}
+	if result != "2025-11-12-A1" {
+		t.Errorf("NextAnalog() = %v, want 2025-11-12-A1", result)
+	}
+
+	backups, err := filepath.Glob(counterFile + ".corrupt-*")
+	if err != nil {
+		t.Fatalf("glob error: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("expected corrupted file to be moved aside, found %d backups", len(backups))
+	}
+}
+
+func TestManager_NoTempFilesLeftBehind(t *testing.T) {
+	counterFile := createTempCounterFile(t)
+	manager, err := New(counterFile)
+	if err != nil {
+		t.Fatalf("Failed to create counter manager: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := manager.NextAnalog("2025-11-12"); err != nil {
+			t.Fatalf("NextAnalog() error = %v", err)
+		}
+	}
+
+	leftovers, err := filepath.Glob(filepath.Join(filepath.Dir(counterFile), ".*.tmp-*"))
+	if err != nil {
+		t.Fatalf("glob error: %v", err)
+	}
+	if len(leftovers) != 0 {
+		t.Fatalf("unexpected temp files: %v", leftovers)
+	}
+}
+
+// TestHelperProcess is not a real test. It is executed as a subprocess by
+// TestManager_ConcurrentProcesses to issue analog numbers from another process.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("STAMP_COUNTER_HELPER") != "1" {
+		return
+	}
+
+	manager, err := Open(Backend(os.Getenv("STAMP_COUNTER_BACKEND")), os.Getenv("STAMP_COUNTER_FILE"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "New() error = %v\n", err)
+		os.Exit(1)
+	}
+
+	iterations, _ := strconv.Atoi(os.Getenv("STAMP_COUNTER_ITERATIONS"))
+	for i := 0; i < iterations; i++ {
+		result, err := manager.NextAnalog("2025-11-12")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "NextAnalog() error = %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(result)
+	}
+	os.Exit(0)
+}
+
+func TestManager_ConcurrentProcesses(t *testing.T) {
+	testConcurrentProcesses(t, BackendJSON, createTempCounterFile(t))
+}
+
+// testConcurrentProcesses has several processes issue analog numbers from
+// the same store and checks that none is handed out twice.
+func testConcurrentProcesses(t *testing.T, backend Backend, counterFile string) {
+	if testing.Short() {
+		t.Skip("skipping multi-process test in short mode")
+	}
+
+	const processes = 12
+	const iterations = 10
+
+	var wg sync.WaitGroup
+	outputs := make([][]byte, processes)
+	errs := make([]error, processes)
+
+	for i := 0; i < processes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+			cmd.Env = append(os.Environ(),
+				"STAMP_COUNTER_HELPER=1",
+				"STAMP_COUNTER_BACKEND="+string(backend),
+				"STAMP_COUNTER_FILE="+counterFile,
+				fmt.Sprintf("STAMP_COUNTER_ITERATIONS=%d", iterations),
+			)
+			cmd.Stderr = os.Stderr
+			outputs[i], errs[i] = cmd.Output()
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for i := 0; i < processes; i++ {
+		if errs[i] != nil {
+			t.Fatalf("helper process %d failed: %v", i, errs[i])
+		}
+		for _, line := range strings.Fields(string(outputs[i])) {
+			if seen[line] {
+				t.Fatalf("duplicate analog number issued: %s", line)
+			}
+			seen[line] = true
+		}
+	}
+
+	if len(seen) != processes*iterations {
+		t.Fatalf("issued %d numbers, want %d", len(seen), processes*iterations)
+	}
+
+	manager, err := Open(backend, counterFile)
+	if err != nil {
+		t.Fatalf("Failed to reopen counter manager: %v", err)
+	}
+	defer manager.Close()
+	count, err := manager.GetAnalogCounter("2025-11-12")
+	if err != nil {
+		t.Fatalf("GetAnalogCounter() error = %v", err)
+	}
+	if count != processes*iterations {
+		t.Errorf("GetAnalogCounter() = %d, want %d", count, processes*iterations)
+	}
+
+	events, err := manager.History(Filter{})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(events) != processes*iterations {
+		t.Fatalf("History() = %d events, want %d", len(events), processes*iterations)
+	}
+	for _, event := range events {
+		if !seen[event.ID] {
+			t.Errorf("history records %q, which was never issued", event.ID)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package counter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Event is one entry of the counter history: an issued ID or a reset.
+type Event struct {
+	Time time.Time `json:"timestamp"`
+	Type string    `json:"type"`
+	ID   string    `json:"id,omitempty"`
+	// Date is the counter key (YYYY-MM-DD) for analog events.
+	Date string `json:"date,omitempty"`
+	// Reset is set on reset events, with Previous holding the counter value
+	// that was discarded. Resets of sequential counters carry the prefix in ID.
+	Reset    bool   `json:"reset,omitempty"`
+	Previous int    `json:"previous,omitempty"`
+	Dir      string `json:"cwd,omitempty"`
+	Host     string `json:"hostname,omitempty"`
+}
+
+// Filter selects history events. Zero fields match everything; Until is
+// exclusive.
+type Filter struct {
+	Since time.Time
+	Until time.Time
+	Type  string
+}
+
+// Match reports whether e passes the filter.
+func (f Filter) Match(e Event) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	return f.Type == "" || strings.EqualFold(f.Type, e.Type)
+}
+
+// HistoryPath returns the history log kept next to counterFile, e.g.
+// counters.json -> counters.history.jsonl.
+func HistoryPath(counterFile string) string {
+	return strings.TrimSuffix(counterFile, filepath.Ext(counterFile)) + ".history.jsonl"
+}
+
+// Record appends an issued ID of the given type to the history, for IDs that
+// are not handed out by the Manager itself.
+func (m *Manager) Record(typ, id string) error {
+	return m.appendEvent(Event{Type: typ, ID: id})
+}
+
+// History returns the events matching filter, oldest first. Lines that
+// cannot be decoded, such as one cut short by a crash, are skipped.
+func (m *Manager) History(filter Filter) ([]Event, error) {
+	if m.file == "" {
+		return nil, nil
+	}
+	f, err := os.Open(HistoryPath(m.file))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if filter.Match(event) {
+			events = append(events, event)
+		}
+	}
+	// Concurrent processes may append slightly out of order.
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, scanner.Err()
+}
+
+// appendEvent stamps event with the time, and the working directory and host
+// unless a remote client supplied them, and appends it to the history. Each
+// event is a single write to a file opened for appending, so concurrent
+// processes do not interleave lines.
+func (m *Manager) appendEvent(event Event) error {
+	if m.file == "" {
+		return nil
+	}
+	event.Time = time.Now()
+	if event.Dir == "" {
+		event.Dir, _ = os.Getwd()
+	}
+	if event.Host == "" {
+		event.Host, _ = os.Hostname()
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(HistoryPath(m.file), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// logEvent appends event to the history, warning instead of failing: the
+// counter change it describes has already been saved.
+func (m *Manager) logEvent(event Event) {
+	if err := m.appendEvent(event); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not update counter history: %v\n", err)
+	}
+}
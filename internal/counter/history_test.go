@@ -0,0 +1,77 @@
+package counter
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHistoryPath(t *testing.T) {
+	if got := HistoryPath("/state/counters.json"); got != "/state/counters.history.jsonl" {
+		t.Errorf("HistoryPath() = %q", got)
+	}
+}
+
+func TestManager_History(t *testing.T) {
+	counterFile := createTempCounterFile(t)
+	manager, err := New(counterFile)
+	if err != nil {
+		t.Fatalf("Failed to create counter manager: %v", err)
+	}
+
+	start := time.Now().Add(-time.Second)
+	manager.NextAnalog("2025-11-12")
+	manager.NextAnalog("2025-11-12")
+	if err := manager.ResetAnalog("2025-11-12"); err != nil {
+		t.Fatalf("ResetAnalog() error = %v", err)
+	}
+	if err := manager.Record("project", "P0042"); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	// A line cut short by a crash must not hide the rest of the log.
+	f, err := os.OpenFile(HistoryPath(counterFile), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"timestamp":"2025-`)
+	f.Close()
+
+	events, err := manager.History(Filter{})
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("History() = %d events, want 4: %+v", len(events), events)
+	}
+
+	wd, _ := os.Getwd()
+	want := []Event{
+		{Type: "analog", ID: "2025-11-12-A1", Date: "2025-11-12"},
+		{Type: "analog", ID: "2025-11-12-A2", Date: "2025-11-12"},
+		{Type: "analog", Date: "2025-11-12", Reset: true, Previous: 2},
+		{Type: "project", ID: "P0042"},
+	}
+	for i, w := range want {
+		got := events[i]
+		if got.Type != w.Type || got.ID != w.ID || got.Date != w.Date || got.Reset != w.Reset || got.Previous != w.Previous {
+			t.Errorf("event %d = %+v, want %+v", i, got, w)
+		}
+		if got.Time.Before(start) || got.Dir != wd || got.Host == "" {
+			t.Errorf("event %d missing context: %+v", i, got)
+		}
+	}
+
+	projects, _ := manager.History(Filter{Type: "PROJECT"})
+	if len(projects) != 1 || projects[0].ID != "P0042" {
+		t.Errorf("History(type project) = %+v", projects)
+	}
+	future, _ := manager.History(Filter{Since: time.Now().Add(time.Hour)})
+	if len(future) != 0 {
+		t.Errorf("History(since future) = %+v, want none", future)
+	}
+	past, _ := manager.History(Filter{Until: start})
+	if len(past) != 0 {
+		t.Errorf("History(until start) = %+v, want none", past)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build !unix
+// +build !unix
+
+package counter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	lockPollInterval = 10 * time.Millisecond
+	lockTimeout      = 10 * time.Second
+	lockStaleAfter   = 30 * time.Second
+)
+
+// acquireLock emulates an exclusive lock by creating path with O_EXCL. Lock
+// files older than lockStaleAfter are assumed to belong to a crashed process
+// and are removed.
+func acquireLock(path string) (func() error, error) {
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() error {
+				return os.Remove(path)
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			os.Remove(path)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for counter lock %s", path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build unix
+// +build unix
+
+package counter
+
+import (
+	"os"
+	"syscall"
+)
+
+// acquireLock takes an exclusive advisory lock on path, blocking until it is
+// available. The lock is released when the returned function is called or the
+// process exits.
+func acquireLock(path string) (func() error, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return func() error {
+		defer f.Close()
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}, nil
+}
@@ -0,0 +1,133 @@
+package counter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dateLayout is the layout of analog counter keys.
+const dateLayout = "2006-01-02"
+
+// ArchivePath returns the file that pruned counters are moved to, e.g.
+// counters.json -> counters.archive.json.
+func ArchivePath(counterFile string) string {
+	return strings.TrimSuffix(counterFile, filepath.Ext(counterFile)) + ".archive.json"
+}
+
+// Cutoff returns the first date kept when pruning entries older than days.
+func Cutoff(now time.Time, days int) string {
+	return now.AddDate(0, 0, -days).Format(dateLayout)
+}
+
+// SetRetention makes the manager archive counters older than days whenever
+// it issues a number. Zero disables automatic pruning.
+func (m *Manager) SetRetention(days int) {
+	m.retention = days
+}
+
+// Prune moves the counters for dates before cutoff (YYYY-MM-DD) to the
+// archive file and returns how many were moved. Keys that are not dates are
+// left alone. With dryRun set nothing is written.
+func (m *Manager) Prune(cutoff string, dryRun bool) (int, error) {
+	var moved int
+	err := m.store.Update(func(c Counters) error {
+		var err error
+		moved, err = m.prune(c, cutoff, dryRun)
+		return err
+	})
+	return moved, err
+}
+
+// prune implements Prune inside a store transaction.
+func (m *Manager) prune(c Counters, cutoff string, dryRun bool) (int, error) {
+	old := make(map[string]int)
+	for _, date := range c.Dates() {
+		if _, err := time.Parse(dateLayout, date); err == nil && date < cutoff {
+			old[date], _ = c.Get(date)
+		}
+	}
+	if len(old) == 0 || dryRun {
+		return len(old), nil
+	}
+	if m.file == "" {
+		return 0, fmt.Errorf("counters can only be archived next to a counter file")
+	}
+
+	archive, err := m.loadArchive()
+	if err != nil {
+		return 0, err
+	}
+	for date, value := range old {
+		archive.Analog[date] = max(archive.Analog[date], value)
+	}
+	// Write the archive first: if the transaction fails, entries are left
+	// in both places and the next prune merges them again.
+	if err := m.saveArchive(archive); err != nil {
+		return 0, err
+	}
+
+	for date := range old {
+		c.Delete(date)
+	}
+	return len(old), nil
+}
+
+// stored returns the counter for date, consulting the archive for dates that
+// were pruned so that backdated notes never reuse a number.
+func (m *Manager) stored(c Counters, date string) (int, error) {
+	if value, ok := c.Get(date); ok {
+		return value, nil
+	}
+	archive, err := m.loadArchive()
+	if err != nil {
+		return 0, err
+	}
+	return archive.Analog[date], nil
+}
+
+// unarchive removes date from the archive, if present.
+func (m *Manager) unarchive(date string) error {
+	archive, err := m.loadArchive()
+	if err != nil {
+		return err
+	}
+	if _, ok := archive.Analog[date]; !ok {
+		return nil
+	}
+	delete(archive.Analog, date)
+	return m.saveArchive(archive)
+}
+
+func (m *Manager) loadArchive() (*Data, error) {
+	archive := &Data{Analog: make(map[string]int)}
+	if m.file == "" {
+		return archive, nil
+	}
+	data, err := os.ReadFile(ArchivePath(m.file))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return archive, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, archive); err != nil {
+		return nil, fmt.Errorf("counter archive %s: %w", ArchivePath(m.file), err)
+	}
+	if archive.Analog == nil {
+		archive.Analog = make(map[string]int)
+	}
+	return archive, nil
+}
+
+func (m *Manager) saveArchive(archive *Data) error {
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(ArchivePath(m.file), data, 0o600)
+}
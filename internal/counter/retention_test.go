@@ -0,0 +1,110 @@
+package counter
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCutoff(t *testing.T) {
+	now := time.Date(2025, 11, 12, 15, 0, 0, 0, time.UTC)
+	if got := Cutoff(now, 90); got != "2025-08-14" {
+		t.Errorf("Cutoff() = %q, want 2025-08-14", got)
+	}
+}
+
+func TestManager_Prune(t *testing.T) {
+	counterFile := createTempCounterFile(t)
+	manager, err := New(counterFile)
+	if err != nil {
+		t.Fatalf("Failed to create counter manager: %v", err)
+	}
+
+	manager.NextAnalog("2025-01-01")
+	manager.NextAnalog("2025-01-01")
+	manager.NextAnalog("2025-06-30")
+	manager.NextAnalog("2025-11-12")
+
+	moved, err := manager.Prune("2025-07-01", true)
+	if err != nil || moved != 2 {
+		t.Fatalf("Prune(dry run) = %d, %v; want 2", moved, err)
+	}
+	if _, err := os.Stat(ArchivePath(counterFile)); !os.IsNotExist(err) {
+		t.Fatal("dry run wrote the archive")
+	}
+
+	moved, err = manager.Prune("2025-07-01", false)
+	if err != nil || moved != 2 {
+		t.Fatalf("Prune() = %d, %v; want 2", moved, err)
+	}
+
+	var live, archive Data
+	readJSON(t, counterFile, &live)
+	readJSON(t, ArchivePath(counterFile), &archive)
+	if len(live.Analog) != 1 || live.Analog["2025-11-12"] != 1 {
+		t.Errorf("counter file after prune = %v", live.Analog)
+	}
+	if archive.Analog["2025-01-01"] != 2 || archive.Analog["2025-06-30"] != 1 {
+		t.Errorf("archive after prune = %v", archive.Analog)
+	}
+
+	// Archived dates keep counting where they left off.
+	if count, _ := manager.GetAnalogCounter("2025-01-01"); count != 2 {
+		t.Errorf("GetAnalogCounter(archived) = %d, want 2", count)
+	}
+	result, err := manager.NextAnalog("2025-01-01")
+	if err != nil || result != "2025-01-01-A3" {
+		t.Errorf("NextAnalog(archived) = %q, %v; want 2025-01-01-A3", result, err)
+	}
+
+	// Pruning again merges into the existing archive.
+	if moved, err := manager.Prune("2025-07-01", false); err != nil || moved != 1 {
+		t.Fatalf("second Prune() = %d, %v; want 1", moved, err)
+	}
+	readJSON(t, ArchivePath(counterFile), &archive)
+	if archive.Analog["2025-01-01"] != 3 {
+		t.Errorf("archive after second prune = %v", archive.Analog)
+	}
+
+	// Resetting an archived date really starts it over.
+	if err := manager.ResetAnalog("2025-06-30"); err != nil {
+		t.Fatalf("ResetAnalog() error = %v", err)
+	}
+	if result, _ := manager.NextAnalog("2025-06-30"); result != "2025-06-30-A1" {
+		t.Errorf("NextAnalog() after reset = %q, want 2025-06-30-A1", result)
+	}
+}
+
+func TestManager_Retention(t *testing.T) {
+	counterFile := createTempCounterFile(t)
+	manager, err := New(counterFile)
+	if err != nil {
+		t.Fatalf("Failed to create counter manager: %v", err)
+	}
+	manager.NextAnalog("2000-01-01")
+
+	manager.SetRetention(30)
+	today := time.Now().Format(dateLayout)
+	manager.NextAnalog(today)
+
+	var live Data
+	readJSON(t, counterFile, &live)
+	if _, ok := live.Analog["2000-01-01"]; ok || live.Analog[today] != 1 {
+		t.Errorf("counter file with retention = %v", live.Analog)
+	}
+	if count, _ := manager.GetAnalogCounter("2000-01-01"); count != 1 {
+		t.Errorf("GetAnalogCounter(archived) = %d, want 1", count)
+	}
+}
+
+func readJSON(t *testing.T, path string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,102 @@
+package counter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Seq identifies a shared sequential counter, such as the "P" prefix of
+// project notes.
+type Seq struct {
+	// Type is the note type recorded in the history, e.g. "project".
+	Type   string `json:"type,omitempty"`
+	Prefix string `json:"prefix"`
+	// Width zero-pads the number in recorded IDs.
+	Width int `json:"width,omitempty"`
+}
+
+// Format renders value as an ID of the counter.
+func (s Seq) Format(value int) string {
+	return fmt.Sprintf("%s%0*d", s.Prefix, s.Width, value)
+}
+
+// key is the store key of the counter. Prefixes match case-insensitively,
+// like sequential IDs on disk; the key is never a date, so pruning leaves it
+// alone.
+func (s Seq) key() string {
+	return "seq:" + strings.ToUpper(s.Prefix)
+}
+
+// Service is the set of counter operations shared by a local Manager and a
+// Client of `stamp serve`.
+type Service interface {
+	NextAnalogAfter(date string, floor int) (int, error)
+	GetAnalogCounter(date string) (int, error)
+	ResetAnalog(date string) error
+	NextSeq(seq Seq, floor int) (int, error)
+	GetSeq(seq Seq) (int, error)
+	ResetSeq(seq Seq) error
+}
+
+var _ Service = (*Manager)(nil)
+
+// NextSeq returns the next value of a sequential counter that is at least
+// floor + 1, typically the highest ID found on disk, and stores it.
+func (m *Manager) NextSeq(seq Seq, floor int) (int, error) {
+	return m.nextSeq(seq, floor, Event{})
+}
+
+func (m *Manager) nextSeq(seq Seq, floor int, origin Event) (int, error) {
+	var next int
+	err := m.store.Update(func(c Counters) error {
+		stored, _ := c.Get(seq.key())
+		next = max(stored, floor) + 1
+		c.Set(seq.key(), next)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	origin.Type, origin.ID = seqType(seq), seq.Format(next)
+	m.logEvent(origin)
+	return next, nil
+}
+
+// GetSeq returns the last value issued by a sequential counter.
+func (m *Manager) GetSeq(seq Seq) (int, error) {
+	var current int
+	err := m.store.View(func(c Counters) error {
+		current, _ = c.Get(seq.key())
+		return nil
+	})
+	return current, err
+}
+
+// ResetSeq forgets a sequential counter.
+func (m *Manager) ResetSeq(seq Seq) error {
+	return m.resetSeq(seq, Event{})
+}
+
+func (m *Manager) resetSeq(seq Seq, origin Event) error {
+	var previous int
+	err := m.store.Update(func(c Counters) error {
+		previous, _ = c.Get(seq.key())
+		c.Delete(seq.key())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	origin.Type, origin.ID, origin.Reset, origin.Previous = seqType(seq), seq.Prefix, true, previous
+	m.logEvent(origin)
+	return nil
+}
+
+func seqType(seq Seq) string {
+	if seq.Type != "" {
+		return seq.Type
+	}
+	return "seq"
+}
@@ -0,0 +1,145 @@
+package counter
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// request is the body of POST requests to the counter service. Host and Dir
+// describe the client, for the history.
+type request struct {
+	Floor int    `json:"floor,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Width int    `json:"width,omitempty"`
+	Host  string `json:"hostname,omitempty"`
+	Dir   string `json:"cwd,omitempty"`
+}
+
+// response is the body of every counter service reply.
+type response struct {
+	ID    string `json:"id,omitempty"`
+	Value int    `json:"value"`
+	Error string `json:"error,omitempty"`
+}
+
+// errBadRequest marks errors caused by the request rather than the store.
+var errBadRequest = errors.New("bad request")
+
+// NewHandler serves m over HTTP/JSON for Client. When token is set, requests
+// must carry it as a bearer token.
+//
+//	POST /v1/analog/{date}/next    {"floor": n}  -> {"id": "2025-11-12-A3", "value": 3}
+//	GET  /v1/analog/{date}                       -> {"value": 2}
+//	POST /v1/analog/{date}/reset
+//	POST /v1/seq/{prefix}/next     {"floor": n, "type": "project", "width": 4}
+//	GET  /v1/seq/{prefix}
+//	POST /v1/seq/{prefix}/reset
+func NewHandler(m *Manager, token string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/analog/{date}/next", serve(func(r *http.Request, req request) (response, error) {
+		date, err := analogDate(r)
+		if err != nil {
+			return response{}, err
+		}
+		value, err := m.nextAnalog(date, req.Floor, req.origin())
+		if err != nil {
+			return response{}, err
+		}
+		return response{ID: FormatAnalog(date, value), Value: value}, nil
+	}))
+	mux.HandleFunc("GET /v1/analog/{date}", serve(func(r *http.Request, req request) (response, error) {
+		date, err := analogDate(r)
+		if err != nil {
+			return response{}, err
+		}
+		value, err := m.GetAnalogCounter(date)
+		return response{Value: value}, err
+	}))
+	mux.HandleFunc("POST /v1/analog/{date}/reset", serve(func(r *http.Request, req request) (response, error) {
+		date, err := analogDate(r)
+		if err != nil {
+			return response{}, err
+		}
+		return response{}, m.resetAnalog(date, req.origin())
+	}))
+
+	mux.HandleFunc("POST /v1/seq/{prefix}/next", serve(func(r *http.Request, req request) (response, error) {
+		seq := req.seq(r)
+		value, err := m.nextSeq(seq, req.Floor, req.origin())
+		if err != nil {
+			return response{}, err
+		}
+		return response{ID: seq.Format(value), Value: value}, nil
+	}))
+	mux.HandleFunc("GET /v1/seq/{prefix}", serve(func(r *http.Request, req request) (response, error) {
+		value, err := m.GetSeq(req.seq(r))
+		return response{Value: value}, err
+	}))
+	mux.HandleFunc("POST /v1/seq/{prefix}/reset", serve(func(r *http.Request, req request) (response, error) {
+		return response{}, m.resetSeq(req.seq(r), req.origin())
+	}))
+
+	if token == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			writeResponse(w, http.StatusUnauthorized, response{Error: "invalid or missing token"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// serve adapts fn to an http.HandlerFunc, decoding the request body of POST
+// requests and encoding the response or error.
+func serve(fn func(*http.Request, request) (response, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req request
+		if r.Method == http.MethodPost && r.ContentLength != 0 {
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+				writeResponse(w, http.StatusBadRequest, response{Error: fmt.Sprintf("invalid request body: %v", err)})
+				return
+			}
+		}
+
+		resp, err := fn(r, req)
+		switch {
+		case errors.Is(err, errBadRequest):
+			writeResponse(w, http.StatusBadRequest, response{Error: err.Error()})
+		case err != nil:
+			writeResponse(w, http.StatusInternalServerError, response{Error: err.Error()})
+		default:
+			writeResponse(w, http.StatusOK, resp)
+		}
+	}
+}
+
+func writeResponse(w http.ResponseWriter, status int, resp response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func analogDate(r *http.Request) (string, error) {
+	date := r.PathValue("date")
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		return "", fmt.Errorf("%w: invalid date %q (want YYYY-MM-DD)", errBadRequest, date)
+	}
+	return date, nil
+}
+
+func (req request) seq(r *http.Request) Seq {
+	return Seq{Type: req.Type, Prefix: r.PathValue("prefix"), Width: req.Width}
+}
+
+func (req request) origin() Event {
+	return Event{Host: req.Host, Dir: req.Dir}
+}
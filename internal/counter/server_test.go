@@ -0,0 +1,142 @@
+package counter
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestService starts a loopback counter service backed by a JSON file and
+// returns its manager and a client whose local fallback is in memory.
+func newTestService(t *testing.T, token string) (*Manager, *Client, *httptest.Server) {
+	t.Helper()
+	server, err := New(createTempCounterFile(t))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ts := httptest.NewServer(NewHandler(server, token))
+	t.Cleanup(ts.Close)
+
+	local, _ := Open(BackendMemory, "")
+	client := NewClient(ts.URL+"/", token, local)
+	client.warnings = &bytes.Buffer{}
+	return server, client, ts
+}
+
+func TestClient_Analog(t *testing.T) {
+	server, client, _ := newTestService(t, "")
+
+	for _, want := range []int{1, 2} {
+		if got, err := client.NextAnalogAfter("2025-11-12", 0); err != nil || got != want {
+			t.Fatalf("NextAnalogAfter() = %d, %v; want %d", got, err, want)
+		}
+	}
+	if got, _ := client.NextAnalogAfter("2025-11-12", 7); got != 8 {
+		t.Errorf("NextAnalogAfter(floor 7) = %d, want 8", got)
+	}
+	if count, err := client.GetAnalogCounter("2025-11-12"); err != nil || count != 8 {
+		t.Errorf("GetAnalogCounter() = %d, %v; want 8", count, err)
+	}
+	if count, _ := server.GetAnalogCounter("2025-11-12"); count != 8 {
+		t.Errorf("server counter = %d, want 8", count)
+	}
+
+	if err := client.ResetAnalog("2025-11-12"); err != nil {
+		t.Fatalf("ResetAnalog() error = %v", err)
+	}
+	if count, _ := client.GetAnalogCounter("2025-11-12"); count != 0 {
+		t.Errorf("GetAnalogCounter() after reset = %d, want 0", count)
+	}
+
+	if _, err := client.NextAnalogAfter("seq:P", 0); err == nil || !strings.Contains(err.Error(), "invalid date") {
+		t.Errorf("NextAnalogAfter(bad date) error = %v", err)
+	}
+	if local, _ := client.local.GetAnalogCounter("2025-11-12"); local != 0 {
+		t.Errorf("local counter used while the service was up: %d", local)
+	}
+}
+
+func TestClient_Seq(t *testing.T) {
+	server, client, _ := newTestService(t, "")
+	project := Seq{Type: "project", Prefix: "P", Width: 4}
+
+	steps := []struct{ floor, want int }{{0, 1}, {10, 11}, {3, 12}}
+	for _, step := range steps {
+		if got, err := client.NextSeq(project, step.floor); err != nil || got != step.want {
+			t.Fatalf("NextSeq(floor %d) = %d, %v; want %d", step.floor, got, err, step.want)
+		}
+	}
+	// Prefixes match case-insensitively.
+	if got, _ := client.GetSeq(Seq{Prefix: "p"}); got != 12 {
+		t.Errorf("GetSeq(p) = %d, want 12", got)
+	}
+
+	events, _ := server.History(Filter{Type: "project"})
+	if len(events) != 3 || events[2].ID != "P0012" {
+		t.Errorf("server history = %+v, want three project events ending with P0012", events)
+	}
+
+	if err := client.ResetSeq(project); err != nil {
+		t.Fatalf("ResetSeq() error = %v", err)
+	}
+	if got, _ := client.GetSeq(project); got != 0 {
+		t.Errorf("GetSeq() after reset = %d, want 0", got)
+	}
+}
+
+func TestHandler_RecordsClientOrigin(t *testing.T) {
+	server, _, ts := newTestService(t, "")
+
+	body := strings.NewReader(`{"hostname": "laptop-a", "cwd": "/home/a/notes"}`)
+	resp, err := http.Post(ts.URL+"/v1/analog/2025-11-12/next", "application/json", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	events, _ := server.History(Filter{})
+	if len(events) != 1 || events[0].Host != "laptop-a" || events[0].Dir != "/home/a/notes" {
+		t.Errorf("history = %+v, want the client's host and directory", events)
+	}
+}
+
+func TestHandler_Token(t *testing.T) {
+	_, client, ts := newTestService(t, "s3cret")
+
+	if _, err := client.NextAnalogAfter("2025-11-12", 0); err != nil {
+		t.Fatalf("NextAnalogAfter() with token error = %v", err)
+	}
+
+	// A wrong token is an error, not a reason to fall back.
+	wrong := NewClient(ts.URL, "guess", client.local)
+	if _, err := wrong.NextAnalogAfter("2025-11-12", 0); err == nil || !strings.Contains(err.Error(), "token") {
+		t.Errorf("NextAnalogAfter() with wrong token error = %v", err)
+	}
+	if local, _ := client.local.GetAnalogCounter("2025-11-12"); local != 0 {
+		t.Errorf("wrong token fell back to local counters: %d", local)
+	}
+}
+
+func TestClient_FallsBackWhenUnavailable(t *testing.T) {
+	_, client, ts := newTestService(t, "")
+	ts.Close()
+	warnings := client.warnings.(*bytes.Buffer)
+
+	for _, want := range []int{1, 2} {
+		if got, err := client.NextAnalogAfter("2025-11-12", 0); err != nil || got != want {
+			t.Fatalf("NextAnalogAfter() = %d, %v; want %d from local counters", got, err, want)
+		}
+	}
+	if got, err := client.NextSeq(Seq{Prefix: "P"}, 41); err != nil || got != 42 {
+		t.Errorf("NextSeq() = %d, %v; want 42 from the floor", got, err)
+	}
+	if err := client.ResetAnalog("2025-11-12"); err == nil {
+		t.Error("ResetAnalog() succeeded without the service")
+	}
+
+	if n := strings.Count(warnings.String(), "unavailable"); n != 1 {
+		t.Errorf("warnings = %q, want exactly one", warnings.String())
+	}
+}
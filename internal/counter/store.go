@@ -0,0 +1,85 @@
+package counter
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Counters is the set of analog counters, keyed by date, that a Store hands
+// to a transaction.
+type Counters interface {
+	Get(date string) (int, bool)
+	Set(date string, value int)
+	Delete(date string)
+	// Dates returns the stored dates in ascending order.
+	Dates() []string
+}
+
+// Store persists analog counters. Update and View run fn in a transaction
+// that is isolated from other stamp processes using the same store; Update
+// commits the changes made by fn when it returns nil.
+type Store interface {
+	Update(fn func(Counters) error) error
+	View(fn func(Counters) error) error
+	Close() error
+}
+
+// Backend names a Store implementation.
+type Backend string
+
+const (
+	// BackendJSON keeps counters in a JSON file guarded by a lock file.
+	BackendJSON Backend = "json"
+	// BackendSQLite keeps counters in an SQLite database, for many
+	// concurrent invocations. It needs a binary built with -tags sqlite.
+	BackendSQLite Backend = "sqlite"
+	// BackendMemory keeps counters for the lifetime of the process.
+	BackendMemory Backend = "memory"
+)
+
+// OpenStore opens the store for backend at path. The memory backend ignores
+// path.
+func OpenStore(backend Backend, path string) (Store, error) {
+	switch backend {
+	case BackendJSON:
+		return openJSONStore(path)
+	case BackendSQLite:
+		return openSQLiteStore(path)
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	}
+	return nil, fmt.Errorf("invalid counter backend %q", backend)
+}
+
+// mapCounters implements Counters over a map, remembering whether it was
+// modified.
+type mapCounters struct {
+	values map[string]int
+	dirty  bool
+}
+
+func (c *mapCounters) Get(date string) (int, bool) {
+	value, ok := c.values[date]
+	return value, ok
+}
+
+func (c *mapCounters) Set(date string, value int) {
+	c.values[date] = value
+	c.dirty = true
+}
+
+func (c *mapCounters) Delete(date string) {
+	if _, ok := c.values[date]; ok {
+		delete(c.values, date)
+		c.dirty = true
+	}
+}
+
+func (c *mapCounters) Dates() []string {
+	dates := make([]string, 0, len(c.values))
+	for date := range c.values {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates
+}
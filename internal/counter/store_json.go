@@ -0,0 +1,127 @@
+package counter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Data stores analog counter information keyed by date.
+type Data struct {
+	Analog map[string]int `json:"analog"` // Date -> counter mapping
+}
+
+// jsonStore keeps counters in a JSON file.
+//
+// Every transaction holds an advisory lock on a sibling ".lock" file and
+// re-reads the counter file, so concurrent stamp processes never hand out
+// the same number.
+type jsonStore struct {
+	mu   sync.Mutex
+	file string
+}
+
+func openJSONStore(file string) (*jsonStore, error) {
+	s := &jsonStore{file: file}
+
+	// Create the file when it is missing
+	err := s.Update(func(c Counters) error {
+		if _, err := os.Stat(s.file); os.IsNotExist(err) {
+			return s.save(&Data{Analog: make(map[string]int)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *jsonStore) Update(fn func(Counters) error) error {
+	return s.transact(fn, true)
+}
+
+func (s *jsonStore) View(fn func(Counters) error) error {
+	return s.transact(fn, false)
+}
+
+func (s *jsonStore) Close() error { return nil }
+
+// lockFile returns the path of the advisory lock guarding the counter file.
+func (s *jsonStore) lockFile() string {
+	return s.file + ".lock"
+}
+
+// transact runs fn while holding both the in-process mutex and the
+// cross-process file lock. The latest on-disk state is loaded before fn runs
+// and saved afterwards when write is set and fn changed it.
+func (s *jsonStore) transact(fn func(Counters) error, write bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(s.file), 0o755); err != nil {
+		return err
+	}
+
+	unlock, err := acquireLock(s.lockFile())
+	if err != nil {
+		return fmt.Errorf("lock counter file: %w", err)
+	}
+	defer unlock()
+
+	data, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	counters := &mapCounters{values: data.Analog}
+	if err := fn(counters); err != nil {
+		return err
+	}
+	if write && counters.dirty {
+		return s.save(data)
+	}
+	return nil
+}
+
+// load reads counter data from file. A missing file yields empty data; a
+// corrupted file is moved aside so it can be inspected, and empty data is used.
+func (s *jsonStore) load() (*Data, error) {
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Data{Analog: make(map[string]int)}, nil
+		}
+		return nil, err
+	}
+
+	var loaded Data
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		backup := fmt.Sprintf("%s.corrupt-%s", s.file, time.Now().Format("20060102-150405"))
+		if renameErr := os.Rename(s.file, backup); renameErr != nil {
+			return nil, fmt.Errorf("counter file %s is corrupted (%v) and could not be moved aside: %w", s.file, err, renameErr)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: Counter file corrupted, moved to %s and starting fresh: %v\n", backup, err)
+		return &Data{Analog: make(map[string]int)}, nil
+	}
+	if loaded.Analog == nil {
+		loaded.Analog = make(map[string]int)
+	}
+
+	return &loaded, nil
+}
+
+// save atomically writes counter data to file by writing a temporary file in
+// the same directory and renaming it over the original.
+func (s *jsonStore) save(data *Data) error {
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.file, encoded, 0o600)
+}
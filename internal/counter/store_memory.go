@@ -0,0 +1,45 @@
+package counter
+
+import "sync"
+
+// MemoryStore keeps counters in memory. It is safe for concurrent use within
+// one process and is meant for tests and throwaway runs.
+type MemoryStore struct {
+	mu     sync.Mutex
+	values map[string]int
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{values: make(map[string]int)}
+}
+
+// Update runs fn and keeps its changes only when it succeeds.
+func (s *MemoryStore) Update(fn func(Counters) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	working := s.copy()
+	if err := fn(&mapCounters{values: working}); err != nil {
+		return err
+	}
+	s.values = working
+	return nil
+}
+
+// View runs fn on a copy of the counters.
+func (s *MemoryStore) View(fn func(Counters) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(&mapCounters{values: s.copy()})
+}
+
+func (s *MemoryStore) Close() error { return nil }
+
+func (s *MemoryStore) copy() map[string]int {
+	values := make(map[string]int, len(s.values))
+	for date, value := range s.values {
+		values[date] = value
+	}
+	return values
+}
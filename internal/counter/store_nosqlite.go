@@ -0,0 +1,9 @@
+//go:build !(sqlite && cgo)
+
+package counter
+
+import "errors"
+
+func openSQLiteStore(path string) (Store, error) {
+	return nil, errors.New("this stamp binary was built without SQLite support; rebuild with CGO_ENABLED=1 and -tags sqlite")
+}
@@ -0,0 +1,138 @@
+//go:build sqlite && cgo
+
+package counter
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteStore keeps counters in an SQLite database. Update transactions
+// start with BEGIN IMMEDIATE, so writers from many processes queue on the
+// database lock instead of failing.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func openSQLiteStore(path string) (Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	dsn := "file:" + path + "?_busy_timeout=10000&_journal_mode=WAL&_txlock=immediate"
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS analog (
+		date  TEXT PRIMARY KEY,
+		value INTEGER NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open counter database %s: %w", path, err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Update(fn func(Counters) error) error {
+	return s.transact(fn, true)
+}
+
+func (s *sqliteStore) View(fn func(Counters) error) error {
+	return s.transact(fn, false)
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *sqliteStore) transact(fn func(Counters) error, write bool) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	counters := &sqlCounters{tx: tx}
+	if err := fn(counters); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if counters.err != nil {
+		tx.Rollback()
+		return counters.err
+	}
+	if !write {
+		return tx.Rollback()
+	}
+	return tx.Commit()
+}
+
+// sqlCounters implements Counters on a transaction. The first error is kept
+// and fails the transaction.
+type sqlCounters struct {
+	tx  *sql.Tx
+	err error
+}
+
+func (c *sqlCounters) Get(date string) (int, bool) {
+	if c.err != nil {
+		return 0, false
+	}
+	var value int
+	err := c.tx.QueryRow(`SELECT value FROM analog WHERE date = ?`, date).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false
+	}
+	if err != nil {
+		c.err = err
+		return 0, false
+	}
+	return value, true
+}
+
+func (c *sqlCounters) Set(date string, value int) {
+	if c.err != nil {
+		return
+	}
+	_, c.err = c.tx.Exec(`INSERT INTO analog (date, value) VALUES (?, ?)
+		ON CONFLICT(date) DO UPDATE SET value = excluded.value`, date, value)
+}
+
+func (c *sqlCounters) Delete(date string) {
+	if c.err != nil {
+		return
+	}
+	_, c.err = c.tx.Exec(`DELETE FROM analog WHERE date = ?`, date)
+}
+
+func (c *sqlCounters) Dates() []string {
+	if c.err != nil {
+		return nil
+	}
+	rows, err := c.tx.Query(`SELECT date FROM analog ORDER BY date`)
+	if err != nil {
+		c.err = err
+		return nil
+	}
+	defer rows.Close()
+
+	var dates []string
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			c.err = err
+			return nil
+		}
+		dates = append(dates, date)
+	}
+	c.err = rows.Err()
+	return dates
+}
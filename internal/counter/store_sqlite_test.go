@@ -0,0 +1,21 @@
+//go:build sqlite && cgo
+
+package counter
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteStore(t *testing.T) {
+	store, err := OpenStore(BackendSQLite, filepath.Join(t.TempDir(), "counters.db"))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	defer store.Close()
+	testStore(t, store)
+}
+
+func TestManager_SQLiteConcurrentProcesses(t *testing.T) {
+	testConcurrentProcesses(t, BackendSQLite, filepath.Join(t.TempDir(), "counters.db"))
+}
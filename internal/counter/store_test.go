@@ -0,0 +1,92 @@
+package counter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testStore checks the behaviour every Store must share.
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+
+	err := store.Update(func(c Counters) error {
+		c.Set("2025-11-13", 2)
+		c.Set("2025-11-12", 5)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// A failed update leaves the store untouched.
+	failure := errors.New("boom")
+	err = store.Update(func(c Counters) error {
+		c.Set("2025-11-12", 99)
+		c.Delete("2025-11-13")
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Update() error = %v, want %v", err, failure)
+	}
+
+	err = store.View(func(c Counters) error {
+		if got := c.Dates(); !reflect.DeepEqual(got, []string{"2025-11-12", "2025-11-13"}) {
+			t.Errorf("Dates() = %v", got)
+		}
+		if value, ok := c.Get("2025-11-12"); !ok || value != 5 {
+			t.Errorf("Get(2025-11-12) = %d, %v; want 5", value, ok)
+		}
+		if _, ok := c.Get("2025-11-14"); ok {
+			t.Error("Get() of a missing date reported ok")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View() error = %v", err)
+	}
+
+	err = store.Update(func(c Counters) error {
+		c.Delete("2025-11-12")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	store.View(func(c Counters) error {
+		if got := c.Dates(); !reflect.DeepEqual(got, []string{"2025-11-13"}) {
+			t.Errorf("Dates() after delete = %v", got)
+		}
+		return nil
+	})
+}
+
+func TestJSONStore(t *testing.T) {
+	store, err := OpenStore(BackendJSON, createTempCounterFile(t))
+	if err != nil {
+		t.Fatalf("OpenStore() error = %v", err)
+	}
+	testStore(t, store)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestManager_MemoryBackend(t *testing.T) {
+	manager, err := Open(BackendMemory, "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	manager.NextAnalog("2025-11-12")
+	if result, _ := manager.NextAnalog("2025-11-12"); result != "2025-11-12-A2" {
+		t.Errorf("NextAnalog() = %q, want 2025-11-12-A2", result)
+	}
+	if err := manager.Record("project", "P0001"); err != nil {
+		t.Errorf("Record() error = %v", err)
+	}
+	if events, err := manager.History(Filter{}); err != nil || len(events) != 0 {
+		t.Errorf("History() = %v, %v; want no history in memory", events, err)
+	}
+}
@@ -0,0 +1,191 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toto/stamp/internal/calendar"
+)
+
+var absoluteLayouts = []struct {
+	layout  string
+	hasTime bool
+}{
+	{time.RFC3339, true},
+	{"2006-01-02T15:04:05", true},
+	{"2006-01-02T15:04", true},
+	{"2006-01-02 15:04:05", true},
+	{"2006-01-02 15:04", true},
+	{"2006-01-02", false},
+	{"20060102", false},
+}
+
+var clockLayouts = []string{"15:04:05", "15:04"}
+
+// ParseDate resolves a user supplied date expression relative to now.
+//
+// Supported forms:
+//   - ISO dates and times: "2025-11-10", "2025-11-10 14:00", "2025-11-10T14:00:05", RFC 3339
+//   - Times of day: "14:00", "14:00:05" (today)
+//   - Keywords: "now", "today", "yesterday", "tomorrow"
+//   - Offsets: "-3d", "+2w", "-90m", "+1h", "-30s"
+//   - Weekdays: "monday", "last monday", "next friday"
+//
+// Date-only expressions may be followed by a time of day ("last monday 14:00");
+// without one, the current time of day is kept. The result uses now's location.
+func ParseDate(expr string, now time.Time) (time.Time, error) {
+	input := strings.TrimSpace(expr)
+	if input == "" {
+		return time.Time{}, fmt.Errorf("empty date expression")
+	}
+
+	loc := now.Location()
+	for _, candidate := range absoluteLayouts {
+		if t, err := time.ParseInLocation(candidate.layout, input, loc); err == nil {
+			if !candidate.hasTime {
+				t = withClock(t, now)
+			}
+			return t, nil
+		}
+	}
+
+	fields := strings.Fields(strings.ToLower(input))
+
+	// A trailing time of day applies to whatever date precedes it.
+	var clock *time.Time
+	if len(fields) > 1 {
+		if t, ok := parseClock(fields[len(fields)-1]); ok {
+			clock = &t
+			fields = fields[:len(fields)-1]
+		}
+	}
+
+	day, err := resolveDay(fields, now, input)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if clock != nil {
+		day = withClock(day, *clock)
+	}
+	return day, nil
+}
+
+func resolveDay(fields []string, now time.Time, input string) (time.Time, error) {
+	if len(fields) == 1 {
+		field := fields[0]
+		switch field {
+		case "now", "today":
+			return now, nil
+		case "yesterday":
+			return now.AddDate(0, 0, -1), nil
+		case "tomorrow":
+			return now.AddDate(0, 0, 1), nil
+		}
+		if t, ok := parseClock(field); ok {
+			return withClock(now, t), nil
+		}
+		if t, ok := parseOffset(field, now); ok {
+			return t, nil
+		}
+		if wd, err := calendar.ParseWeekday(field); err == nil {
+			return previousWeekday(now, wd, true), nil
+		}
+		if t, err := resolveAbsolute(field, now); err == nil {
+			return t, nil
+		}
+	}
+
+	if len(fields) == 2 {
+		if wd, err := calendar.ParseWeekday(fields[1]); err == nil {
+			switch fields[0] {
+			case "last":
+				return previousWeekday(now, wd, false), nil
+			case "next":
+				return nextWeekday(now, wd), nil
+			case "this":
+				return previousWeekday(now, wd, true), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised date expression %q", input)
+}
+
+func resolveAbsolute(field string, now time.Time) (time.Time, error) {
+	for _, candidate := range absoluteLayouts {
+		if candidate.hasTime {
+			continue
+		}
+		if t, err := time.ParseInLocation(candidate.layout, field, now.Location()); err == nil {
+			return withClock(t, now), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("not an absolute date")
+}
+
+func parseClock(value string) (time.Time, bool) {
+	for _, layout := range clockLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseOffset(value string, now time.Time) (time.Time, bool) {
+	if len(value) < 3 || (value[0] != '+' && value[0] != '-') {
+		return time.Time{}, false
+	}
+
+	unit := value[len(value)-1]
+	amount, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	if value[0] == '-' {
+		amount = -amount
+	}
+
+	switch unit {
+	case 's':
+		return now.Add(time.Duration(amount) * time.Second), true
+	case 'm':
+		return now.Add(time.Duration(amount) * time.Minute), true
+	case 'h':
+		return now.Add(time.Duration(amount) * time.Hour), true
+	case 'd':
+		return now.AddDate(0, 0, amount), true
+	case 'w':
+		return now.AddDate(0, 0, 7*amount), true
+	case 'y':
+		return now.AddDate(amount, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+// previousWeekday returns the most recent wd before now. When includeToday is
+// set and now already falls on wd, now is returned.
+func previousWeekday(now time.Time, wd time.Weekday, includeToday bool) time.Time {
+	diff := (int(now.Weekday()) - int(wd) + 7) % 7
+	if diff == 0 && !includeToday {
+		diff = 7
+	}
+	return now.AddDate(0, 0, -diff)
+}
+
+// nextWeekday returns the first wd strictly after now.
+func nextWeekday(now time.Time, wd time.Weekday) time.Time {
+	diff := (int(wd) - int(now.Weekday()) + 7) % 7
+	if diff == 0 {
+		diff = 7
+	}
+	return now.AddDate(0, 0, diff)
+}
+
+// withClock combines the date of day with the time of day from clock.
+func withClock(day, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		clock.Hour(), clock.Minute(), clock.Second(), 0, day.Location())
+}
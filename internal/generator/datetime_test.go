@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 11, 12, 15, 34, 45, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"now", now},
+		{"today", now},
+		{"yesterday", time.Date(2025, 11, 11, 15, 34, 45, 0, time.UTC)},
+		{"tomorrow", time.Date(2025, 11, 13, 15, 34, 45, 0, time.UTC)},
+		{"2025-11-10", time.Date(2025, 11, 10, 15, 34, 45, 0, time.UTC)},
+		{"2025-11-10 09:15", time.Date(2025, 11, 10, 9, 15, 0, 0, time.UTC)},
+		{"2025-11-10T09:15:30", time.Date(2025, 11, 10, 9, 15, 30, 0, time.UTC)},
+		{"14:00", time.Date(2025, 11, 12, 14, 0, 0, 0, time.UTC)},
+		{"-3d", time.Date(2025, 11, 9, 15, 34, 45, 0, time.UTC)},
+		{"+2w", time.Date(2025, 11, 26, 15, 34, 45, 0, time.UTC)},
+		{"-90m", time.Date(2025, 11, 12, 14, 4, 45, 0, time.UTC)},
+		{"monday", time.Date(2025, 11, 10, 15, 34, 45, 0, time.UTC)},
+		{"wednesday", now},
+		{"last wednesday", time.Date(2025, 11, 5, 15, 34, 45, 0, time.UTC)},
+		{"last monday 14:00", time.Date(2025, 11, 10, 14, 0, 0, 0, time.UTC)},
+		{"next Friday", time.Date(2025, 11, 14, 15, 34, 45, 0, time.UTC)},
+		{"yesterday 08:30", time.Date(2025, 11, 11, 8, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseDate(tt.expr, now)
+			if err != nil {
+				t.Fatalf("ParseDate(%q) error = %v", tt.expr, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDateInvalid(t *testing.T) {
+	now := time.Date(2025, 11, 12, 15, 34, 45, 0, time.UTC)
+
+	for _, expr := range []string{"", "someday", "-3x", "last", "2025-13-01"} {
+		if _, err := ParseDate(expr, now); err == nil {
+			t.Errorf("ParseDate(%q) expected error", expr)
+		}
+	}
+}
@@ -3,13 +3,25 @@ package generator
 import (
 	"fmt"
 	"time"
+
+	"github.com/toto/stamp/internal/calendar"
 )
 
+// Formatter renders a moment in time as a note name. It is used for formats
+// that cannot be expressed as a Go time layout, such as week numbers.
+type Formatter func(time.Time) string
+
 // Generator handles timestamp generation with timezone support
 type Generator struct {
-	location      *time.Location
-	defaultLayout string
-	dailyLayout   string
+	location        *time.Location
+	clock           func() time.Time
+	weekStart       time.Weekday
+	defaultFormat   Formatter
+	dailyFormat     Formatter
+	weeklyFormat    Formatter
+	monthlyFormat   Formatter
+	quarterlyFormat Formatter
+	yearlyFormat    Formatter
 }
 
 // New creates a new generator with the specified timezone
@@ -25,25 +37,44 @@ func New(timezone string) (*Generator, error) {
 	}
 
 	return &Generator{
-		location:      loc,
-		defaultLayout: "2006-01-02-1504",
-		dailyLayout:   "2006-01-02",
+		location:  loc,
+		clock:     time.Now,
+		weekStart: time.Monday,
 	}, nil
 }
 
 // now returns the current time in the configured timezone
 func (g *Generator) now() time.Time {
-	return time.Now().In(g.location)
+	return g.clock().In(g.location)
+}
+
+// Now returns the generator's notion of the current time in its timezone.
+func (g *Generator) Now() time.Time {
+	return g.now()
+}
+
+// SetTime pins the generator to t so that every method renders that moment
+// instead of the wall clock. Used for backdated and future notes.
+func (g *Generator) SetTime(t time.Time) {
+	g.clock = func() time.Time { return t }
 }
 
 // Default generates YYYY-MM-DD-HHMM format
 func (g *Generator) Default() string {
-	return g.now().Format(g.defaultLayout)
+	now := g.now()
+	if g.defaultFormat != nil {
+		return g.defaultFormat(now)
+	}
+	return now.Format("2006-01-02-1504")
 }
 
 // Daily generates YYYY-MM-DD format
 func (g *Generator) Daily() string {
-	return g.now().Format(g.dailyLayout)
+	now := g.now()
+	if g.dailyFormat != nil {
+		return g.dailyFormat(now)
+	}
+	return now.Format("2006-01-02")
 }
 
 // Fleeting generates YYYY-MM-DD-FHHMMSS format
@@ -62,9 +93,38 @@ func (g *Generator) Voice() string {
 		now.Hour(), now.Minute(), now.Second())
 }
 
+// SetWeekStart sets the first day of the week used by Weekly. Monday (the
+// default) yields ISO 8601 weeks; other days use weeks where week 1 contains
+// January 1st.
+func (g *Generator) SetWeekStart(start time.Weekday) {
+	g.weekStart = start
+}
+
+// Weekly generates YYYY-Www format using week-numbering years
+func (g *Generator) Weekly() string {
+	now := g.now()
+	if g.weeklyFormat != nil {
+		return g.weeklyFormat(now)
+	}
+	year, week := calendar.Week(now, g.weekStart)
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+// Quarterly generates YYYY-QN format
+func (g *Generator) Quarterly() string {
+	now := g.now()
+	if g.quarterlyFormat != nil {
+		return g.quarterlyFormat(now)
+	}
+	return fmt.Sprintf("%04d-Q%d", now.Year(), calendar.Quarter(now))
+}
+
 // Monthly generates YYYY-MM format
 func (g *Generator) Monthly() string {
 	now := g.now()
+	if g.monthlyFormat != nil {
+		return g.monthlyFormat(now)
+	}
 	return fmt.Sprintf("%04d-%02d",
 		now.Year(), now.Month())
 }
@@ -72,9 +132,17 @@ func (g *Generator) Monthly() string {
 // Yearly generates YYYY format
 func (g *Generator) Yearly() string {
 	now := g.now()
+	if g.yearlyFormat != nil {
+		return g.yearlyFormat(now)
+	}
 	return fmt.Sprintf("%04d", now.Year())
 }
 
+// Render formats the generator's current time with a custom formatter
+func (g *Generator) Render(format Formatter) string {
+	return format(g.now())
+}
+
 // GetCurrentDate returns the current date in YYYY-MM-DD format
 func (g *Generator) GetCurrentDate() string {
 	return g.Daily()
@@ -96,16 +164,32 @@ func (g *Generator) FormatProject(num int, title string) string {
 
 // LayoutOverrides adjust dynamic layouts applied to generator output.
 type LayoutOverrides struct {
-	Default string
-	Daily   string
+	Default   Formatter
+	Daily     Formatter
+	Weekly    Formatter
+	Monthly   Formatter
+	Quarterly Formatter
+	Yearly    Formatter
 }
 
 // ApplyLayouts updates the generator with new layouts when provided.
 func (g *Generator) ApplyLayouts(overrides LayoutOverrides) {
-	if overrides.Default != "" {
-		g.defaultLayout = overrides.Default
+	if overrides.Default != nil {
+		g.defaultFormat = overrides.Default
+	}
+	if overrides.Daily != nil {
+		g.dailyFormat = overrides.Daily
+	}
+	if overrides.Weekly != nil {
+		g.weeklyFormat = overrides.Weekly
+	}
+	if overrides.Monthly != nil {
+		g.monthlyFormat = overrides.Monthly
+	}
+	if overrides.Quarterly != nil {
+		g.quarterlyFormat = overrides.Quarterly
 	}
-	if overrides.Daily != "" {
-		g.dailyLayout = overrides.Daily
+	if overrides.Yearly != nil {
+		g.yearlyFormat = overrides.Yearly
 	}
 }
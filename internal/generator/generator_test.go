@@ -204,8 +204,8 @@ func TestGenerator_ApplyLayouts(t *testing.T) {
 	}
 
 	gen.ApplyLayouts(LayoutOverrides{
-		Default: "20060102-1504",
-		Daily:   "20060102",
+		Default: func(t time.Time) string { return t.Format("20060102-1504") },
+		Daily:   func(t time.Time) string { return t.Format("20060102") },
 	})
 
 	if defaultStamp := gen.Default(); !regexp.MustCompile(`^\d{8}-\d{4}$`).MatchString(defaultStamp) {
@@ -215,6 +215,18 @@ func TestGenerator_ApplyLayouts(t *testing.T) {
 	if daily := gen.Daily(); !regexp.MustCompile(`^\d{8}$`).MatchString(daily) {
 		t.Fatalf("unexpected daily layout result: %q", daily)
 	}
+
+	gen.SetTime(time.Date(2025, 11, 10, 9, 5, 7, 0, time.UTC))
+	gen.ApplyLayouts(LayoutOverrides{
+		Monthly: func(t time.Time) string { return t.Format("2006-01 January") },
+		Yearly:  func(t time.Time) string { return "Year " + t.Format("2006") },
+	})
+	if got := gen.Monthly(); got != "2025-11 November" {
+		t.Errorf("Monthly() with override = %v, want 2025-11 November", got)
+	}
+	if got := gen.Yearly(); got != "Year 2025" {
+		t.Errorf("Yearly() with override = %v, want Year 2025", got)
+	}
 }
 
 func TestGenerator_TimezoneConsistency(t *testing.T) {
@@ -233,3 +245,60 @@ func TestGenerator_TimezoneConsistency(t *testing.T) {
 		t.Errorf("Daily() with UTC timezone = %v, should start with today's date in UTC", daily)
 	}
 }
+
+func TestGenerator_SetTime(t *testing.T) {
+	gen, err := New("UTC")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	gen.SetTime(time.Date(2025, 11, 10, 9, 5, 7, 0, time.UTC))
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"default", gen.Default(), "2025-11-10-0905"},
+		{"daily", gen.Daily(), "2025-11-10"},
+		{"fleeting", gen.Fleeting(), "2025-11-10-F090507"},
+		{"voice", gen.Voice(), "2025-11-10-VT090507"},
+		{"monthly", gen.Monthly(), "2025-11"},
+		{"yearly", gen.Yearly(), "2025"},
+		{"current date", gen.GetCurrentDate(), "2025-11-10"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestGenerator_WeeklyAndQuarterly(t *testing.T) {
+	gen, err := New("UTC")
+	if err != nil {
+		t.Fatalf("Failed to create generator: %v", err)
+	}
+
+	gen.SetTime(time.Date(2025, 12, 28, 10, 0, 0, 0, time.UTC))
+
+	if got := gen.Weekly(); got != "2025-W52" {
+		t.Errorf("Weekly() = %v, want 2025-W52", got)
+	}
+	if got := gen.Quarterly(); got != "2025-Q4" {
+		t.Errorf("Quarterly() = %v, want 2025-Q4", got)
+	}
+
+	gen.SetWeekStart(time.Sunday)
+	if got := gen.Weekly(); got != "2026-W01" {
+		t.Errorf("Weekly() with Sunday start = %v, want 2026-W01", got)
+	}
+
+	gen.ApplyLayouts(LayoutOverrides{
+		Weekly: func(t time.Time) string { return "week-" + t.Format("2006") },
+	})
+	if got := gen.Weekly(); got != "week-2025" {
+		t.Errorf("Weekly() with override = %v, want week-2025", got)
+	}
+}
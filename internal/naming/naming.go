@@ -0,0 +1,200 @@
+// Package naming turns free-form note titles into filename-safe strings
+// according to a configurable policy.
+package naming
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Title styles.
+const (
+	StyleKeep  = "keep"  // keep the title as typed
+	StyleKebab = "kebab" // lowercase words joined by hyphens
+	StyleSnake = "snake" // lowercase words joined by underscores
+)
+
+// Filesystem targets whose forbidden characters are replaced.
+const (
+	TargetAuto     = "auto" // the operating system stamp runs on
+	TargetUnix     = "unix"
+	TargetDarwin   = "darwin"
+	TargetWindows  = "windows"
+	TargetPortable = "portable" // safe on every supported system
+)
+
+// TitlePolicy describes how titles are rewritten before they become part of
+// a filename. The zero value keeps titles as typed, replacing only characters
+// the current operating system rejects.
+type TitlePolicy struct {
+	Style string
+	// Transliterate replaces non-ASCII letters with ASCII approximations
+	// (é -> e, ß -> ss) and drops characters that have none.
+	Transliterate bool
+	// MaxLength limits the title to this many characters; 0 means unlimited.
+	MaxLength int
+	Target    string
+	// Replacement substitutes forbidden characters; defaults to "-".
+	Replacement string
+}
+
+// Validate reports unknown styles or targets and unsafe replacements.
+func (p TitlePolicy) Validate() error {
+	switch p.Style {
+	case "", StyleKeep, StyleKebab, StyleSnake:
+	default:
+		return fmt.Errorf("invalid title style %q (want keep, kebab or snake)", p.Style)
+	}
+	switch p.Target {
+	case "", TargetAuto, TargetUnix, TargetDarwin, TargetWindows, TargetPortable:
+	default:
+		return fmt.Errorf("invalid title target %q (want auto, unix, darwin, windows or portable)", p.Target)
+	}
+	if p.MaxLength < 0 {
+		return fmt.Errorf("invalid title max_length %d", p.MaxLength)
+	}
+	for _, r := range p.Replacement {
+		if forbidden(TargetPortable, r) {
+			return fmt.Errorf("title replacement %q contains a forbidden character", p.Replacement)
+		}
+	}
+	return nil
+}
+
+// Apply rewrites title according to the policy. The result may be empty when
+// nothing usable remains.
+func (p TitlePolicy) Apply(title string) string {
+	if p.Transliterate {
+		title = transliterate(title)
+	}
+	title = strings.Join(strings.Fields(title), " ")
+
+	switch p.Style {
+	case StyleKebab:
+		title = slugify(title, "-")
+	case StyleSnake:
+		title = slugify(title, "_")
+	}
+
+	title = p.replaceForbidden(title)
+
+	if p.MaxLength > 0 {
+		title = truncate(title, p.MaxLength)
+	}
+	if p.target() == TargetWindows || p.target() == TargetPortable {
+		// Windows drops trailing dots and spaces from names.
+		title = strings.TrimRight(title, ". ")
+	}
+	return title
+}
+
+func (p TitlePolicy) target() string {
+	if p.Target == "" || p.Target == TargetAuto {
+		switch runtime.GOOS {
+		case "windows":
+			return TargetWindows
+		case "darwin":
+			return TargetDarwin
+		}
+		return TargetUnix
+	}
+	return p.Target
+}
+
+func (p TitlePolicy) replaceForbidden(title string) string {
+	replacement := p.Replacement
+	if replacement == "" {
+		replacement = "-"
+	}
+	target := p.target()
+
+	// Runs such as "?!" collapse into a single replacement; runs at either
+	// end are dropped, with the spaces next to them.
+	var b strings.Builder
+	pending, leading := false, false
+	for i, r := range title {
+		if forbidden(target, r) {
+			pending = true
+			leading = leading || i == 0
+			continue
+		}
+		if pending && b.Len() > 0 {
+			b.WriteString(replacement)
+		}
+		pending = false
+		b.WriteRune(r)
+	}
+
+	result := b.String()
+	if leading {
+		result = strings.TrimLeft(result, " ")
+	}
+	if pending {
+		result = strings.TrimRight(result, " ")
+	}
+	return result
+}
+
+func forbidden(target string, r rune) bool {
+	if r == '/' || r == 0 {
+		return true
+	}
+	switch target {
+	case TargetDarwin:
+		return r == ':'
+	case TargetWindows, TargetPortable:
+		return r < 32 || strings.ContainsRune(`<>:"\|?*`, r)
+	}
+	return false
+}
+
+// slugify lowercases title and joins its runs of letters and digits with sep.
+func slugify(title, sep string) string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range title {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		if r == '\'' || r == '’' {
+			// Keep contractions together: "don't" -> "dont".
+			continue
+		}
+		flush()
+	}
+	flush()
+	return strings.Join(words, sep)
+}
+
+// truncate shortens title to at most max characters, preferring to cut at a
+// word boundary in the second half of the allowed length.
+func truncate(title string, max int) string {
+	if utf8.RuneCountInString(title) <= max {
+		return title
+	}
+	runes := []rune(title)
+	cut := max
+	if !isBoundary(runes[cut]) {
+		for i := cut - 1; i > max/2; i-- {
+			if isBoundary(runes[i]) {
+				cut = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), isBoundary)
+}
+
+func isBoundary(r rune) bool {
+	return r == ' ' || r == '-' || r == '_'
+}
@@ -0,0 +1,69 @@
+package naming
+
+import "testing"
+
+func TestTitlePolicy_Apply(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy TitlePolicy
+		title  string
+		want   string
+	}{
+		{"keep unix", TitlePolicy{Target: TargetUnix}, "Q3: Design/Review?", "Q3: Design-Review?"},
+		{"keep darwin", TitlePolicy{Target: TargetDarwin}, "Q3: Design/Review?", "Q3- Design-Review?"},
+		{"keep windows", TitlePolicy{Target: TargetWindows}, "Q3: Design/Review?", "Q3- Design-Review"},
+		{"windows custom replacement", TitlePolicy{Target: TargetWindows, Replacement: "_"}, `a<b>c"d|e*f`, "a_b_c_d_e_f"},
+		{"windows trailing dots", TitlePolicy{Target: TargetWindows}, "Notes...", "Notes"},
+		{"kebab", TitlePolicy{Style: StyleKebab}, "Q3: Design/Review?", "q3-design-review"},
+		{"snake", TitlePolicy{Style: StyleSnake}, "  New CLI Tool  ", "new_cli_tool"},
+		{"kebab contractions", TitlePolicy{Style: StyleKebab}, "Don't Panic", "dont-panic"},
+		{"kebab keeps unicode", TitlePolicy{Style: StyleKebab}, "Café Öffnung", "café-öffnung"},
+		{"transliterate", TitlePolicy{Transliterate: true}, "Café Öffnung Straße", "Cafe Oeffnung Strasse"},
+		{"transliterate kebab", TitlePolicy{Style: StyleKebab, Transliterate: true}, "Łódź — plan", "lodz-plan"},
+		{"transliterate cyrillic", TitlePolicy{Transliterate: true}, "Привет мир", "Privet mir"},
+		{"transliterate georgian", TitlePolicy{Style: StyleKebab, Transliterate: true}, "გამარჯობა", "gamarjoba"},
+		{"transliterate drops emoji", TitlePolicy{Transliterate: true}, "Launch 🚀 day", "Launch day"},
+		{"max length word boundary", TitlePolicy{MaxLength: 16}, "Quarterly planning session", "Quarterly"},
+		{"max length kebab", TitlePolicy{Style: StyleKebab, MaxLength: 20}, "Quarterly planning session notes", "quarterly-planning"},
+		{"max length hard cut", TitlePolicy{MaxLength: 5}, "Supercalifragilistic", "Super"},
+		{"max length runes", TitlePolicy{MaxLength: 3}, "日本語のノート", "日本語"},
+		{"only forbidden", TitlePolicy{Target: TargetWindows}, "???", ""},
+		{"keeps dashes at the ends", TitlePolicy{Target: TargetUnix}, "-draft-", "-draft-"},
+		{"drops replaced ends", TitlePolicy{Target: TargetUnix}, "/draft/", "draft"},
+		{"drops spaces next to replaced ends", TitlePolicy{Target: TargetWindows}, "? Draft ?", "Draft"},
+		{"multi-character replacement", TitlePolicy{Target: TargetWindows, Replacement: "_-"}, "-_a?b_-", "-_a_-b_-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Apply(tt.title); got != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitlePolicy_Validate(t *testing.T) {
+	valid := []TitlePolicy{
+		{},
+		{Style: StyleKebab, Target: TargetPortable, MaxLength: 40, Replacement: "_"},
+		{Style: StyleKeep, Target: TargetAuto},
+	}
+	for _, p := range valid {
+		if err := p.Validate(); err != nil {
+			t.Errorf("Validate(%+v) error = %v", p, err)
+		}
+	}
+
+	invalid := []TitlePolicy{
+		{Style: "camel"},
+		{Target: "plan9"},
+		{MaxLength: -1},
+		{Replacement: ":"},
+	}
+	for _, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("Validate(%+v) error = nil, want error", p)
+		}
+	}
+}
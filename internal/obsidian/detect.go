@@ -4,15 +4,28 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 )
 
-// Layouts captures Go time layouts that can be applied when the CLI
-// executes inside an Obsidian vault.
+// Layouts captures the note name formats to apply when the CLI executes
+// inside an Obsidian vault, as renderers. DefaultLayout and DailyLayout are
+// the equivalent Go time layouts used to parse names; they are empty when the
+// format has no Go equivalent, such as one with ordinals.
 type Layouts struct {
-	Default string
-	Daily   string
+	Default       func(time.Time) string
+	Daily         func(time.Time) string
+	Weekly        func(time.Time) string
+	Monthly       func(time.Time) string
+	Quarterly     func(time.Time) string
+	Yearly        func(time.Time) string
+	DefaultLayout string
+	DailyLayout   string
+	MonthlyLayout string
+	YearlyLayout  string
 }
 
 // Result describes detected Obsidian metadata for the current working directory.
@@ -20,11 +33,62 @@ type Result struct {
 	InVault   bool
 	VaultPath string
 	Layouts   Layouts
+	// Notes holds the plugin settings of each note kind (default, daily,
+	// weekly, monthly, quarterly, yearly) that the vault configures.
+	Notes map[string]NoteSettings
+	// Diagnostics lists the problems strict mode found in formats. Formats
+	// with any problem other than a warning are rejected.
+	Diagnostics []Diagnostic
+}
+
+// NoteSettings describe how the vault names and places one kind of note.
+type NoteSettings struct {
+	// Format is the Moment format of note names, empty for the built-in one.
+	Format string `json:"format"`
+	// Folder is the vault-relative folder new notes go to, empty for the
+	// vault root.
+	Folder string `json:"folder"`
+	// Template is the vault-relative path of the template note, as stored
+	// by Obsidian (usually without the .md extension).
+	Template string `json:"template"`
+}
+
+// TemplatePath returns the vault-relative path of the template file, or ""
+// when none is configured.
+func (n NoteSettings) TemplatePath() string {
+	template := strings.Trim(n.Template, "/")
+	if template == "" {
+		return ""
+	}
+	if !strings.HasSuffix(strings.ToLower(template), ".md") {
+		template += ".md"
+	}
+	return template
+}
+
+// Path returns the vault-relative, slash-separated path of the note named
+// filename.
+func (n NoteSettings) Path(filename string) string {
+	return path.Join(strings.Trim(n.Folder, "/"), filename)
+}
+
+// Options adjust detection.
+type Options struct {
+	// Strict rejects formats with unsupported or ambiguous tokens, leaving
+	// their layouts unset so that the built-in formats apply.
+	Strict bool
 }
 
 // Detect attempts to discover whether startPath is within an Obsidian vault and,
-// when it is, extracts time formats from known plugins (Daily Notes and Unique Note Creator).
+// when it is, extracts time formats from known plugins (Daily Notes, Periodic Notes,
+// the core Unique note creator, formerly Zettelkasten prefixer, and the community
+// Unique Note Creator).
 func Detect(startPath string) (*Result, error) {
+	return DetectWith(startPath, Options{})
+}
+
+// DetectWith is Detect with options.
+func DetectWith(startPath string, opts Options) (*Result, error) {
 	absStart, err := filepath.Abs(startPath)
 	if err != nil {
 		return nil, err
@@ -42,12 +106,10 @@ func Detect(startPath string) (*Result, error) {
 	res := &Result{
 		InVault:   true,
 		VaultPath: vaultPath,
+		Notes:     map[string]NoteSettings{},
 	}
 
-	layouts, err := collectLayouts(vaultPath)
-	res.Layouts = layouts
-
-	if err != nil {
+	if err := collectLayouts(res, opts); err != nil {
 		return res, err
 	}
 
@@ -75,60 +137,137 @@ func findVault(start string) (string, error) {
 	}
 }
 
-func collectLayouts(vaultPath string) (Layouts, error) {
-	var layouts Layouts
+func collectLayouts(res *Result, opts Options) error {
+	vaultPath := res.VaultPath
 	var firstErr error
 
-	if format, err := detectDailyNotesFormat(vaultPath); err == nil && format != "" {
-		if goLayout, ok := momentToGoLayout(format); ok {
-			layouts.Daily = goLayout
+	// apply records the settings of a note kind and sets render, and layout
+	// when given, from their format unless strict mode finds problems with it.
+	apply := func(note, source string, settings NoteSettings, render *func(time.Time) string, layout *string) {
+		if settings.Format != "" && opts.Strict {
+			rejected := false
+			for _, problem := range checkFormat(settings.Format) {
+				problem.Note, problem.Source = note, source
+				res.Diagnostics = append(res.Diagnostics, problem)
+				rejected = rejected || !problem.Warning
+			}
+			if rejected {
+				settings.Format = ""
+			}
+		}
+		if settings.Format != "" {
+			if fn, ok := momentFormatter(settings.Format); ok {
+				*render = fn
+				if layout != nil {
+					*layout, _ = momentToGoLayout(settings.Format)
+				}
+			}
+		}
+		if settings != (NoteSettings{}) {
+			res.Notes[note] = settings
 		}
-	} else if err != nil && firstErr == nil {
+	}
+
+	periodic, err := detectPeriodicNotes(vaultPath)
+	if err != nil {
 		firstErr = err
 	}
 
-	if format, err := detectUniqueNoteCreatorFormat(vaultPath); err == nil && format != "" {
-		if goLayout, ok := momentToGoLayout(format); ok {
-			layouts.Default = goLayout
+	// Periodic Notes takes over daily notes when its daily period is enabled.
+	daily, dailySource := periodic.Daily, periodicNotesSettings
+	if !periodic.DailyEnabled {
+		if daily, dailySource, err = detectDailyNotes(vaultPath); err != nil && firstErr == nil {
+			firstErr = err
 		}
-	} else if err != nil && firstErr == nil {
+	}
+	apply("daily", dailySource, daily, &res.Layouts.Daily, &res.Layouts.DailyLayout)
+
+	unique, uniqueSource, err := detectUniqueNotes(vaultPath)
+	if err != nil && firstErr == nil {
 		firstErr = err
 	}
+	apply("default", uniqueSource, unique, &res.Layouts.Default, &res.Layouts.DefaultLayout)
+
+	apply("weekly", periodicNotesSettings, periodic.Weekly, &res.Layouts.Weekly, nil)
+	apply("monthly", periodicNotesSettings, periodic.Monthly, &res.Layouts.Monthly, &res.Layouts.MonthlyLayout)
+	apply("quarterly", periodicNotesSettings, periodic.Quarterly, &res.Layouts.Quarterly, nil)
+	apply("yearly", periodicNotesSettings, periodic.Yearly, &res.Layouts.Yearly, &res.Layouts.YearlyLayout)
 
-	return layouts, firstErr
+	return firstErr
 }
 
-func detectDailyNotesFormat(vaultPath string) (string, error) {
+// Settings files of the supported plugins, relative to the vault.
+var (
+	dailyNotesSettings        = filepath.Join(".obsidian", "daily-notes.json")
+	appSettings               = filepath.Join(".obsidian", "app.json")
+	zkPrefixerSettings        = filepath.Join(".obsidian", "zk-prefixer.json")
+	uniqueNoteCreatorSettings = filepath.Join(".obsidian", "plugins", "unique-note-creator", "data.json")
+	periodicNotesSettings     = filepath.Join(".obsidian", "plugins", "periodic-notes", "data.json")
+)
+
+// detectDailyNotes returns the Daily Notes settings and the settings file they
+// came from.
+func detectDailyNotes(vaultPath string) (settings NoteSettings, source string, err error) {
 	if !isCorePluginEnabled(vaultPath, "daily-notes") {
-		return "", nil
+		return NoteSettings{}, "", nil
 	}
 
-	if format, err := loadDailyNotesJSON(filepath.Join(vaultPath, ".obsidian", "daily-notes.json")); err != nil {
+	if settings, err := loadNoteSettings(filepath.Join(vaultPath, dailyNotesSettings)); err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
-			return "", err
+			return NoteSettings{}, "", err
 		}
-	} else if format != "" {
-		return format, nil
+	} else if settings != (NoteSettings{}) {
+		return settings, dailyNotesSettings, nil
 	}
 
-	if format, err := loadDailyNotesFromApp(filepath.Join(vaultPath, ".obsidian", "app.json")); err != nil {
+	if settings, err := loadDailyNotesFromApp(filepath.Join(vaultPath, appSettings)); err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
-			return "", err
+			return NoteSettings{}, "", err
 		}
-	} else if format != "" {
-		return format, nil
+	} else if settings != (NoteSettings{}) {
+		return settings, appSettings, nil
 	}
 
-	return "", nil
+	return NoteSettings{}, "", nil
 }
 
-func detectUniqueNoteCreatorFormat(vaultPath string) (string, error) {
-	if !isCommunityPluginEnabled(vaultPath, "unique-note-creator") && !pluginDirectoryExists(vaultPath, "unique-note-creator") {
-		return "", nil
+// zkPrefixerDefault is the format of the core Unique note creator plugin when
+// none is configured.
+const zkPrefixerDefault = "YYYYMMDDHHmm"
+
+// detectUniqueNotes returns the settings for default notes and the settings
+// file they came from. The community Unique Note Creator wins when it is
+// enabled, since it is installed to replace the core plugin; then comes the
+// core Unique note creator (zk-prefixer), then a community plugin that is
+// installed but not enabled.
+func detectUniqueNotes(vaultPath string) (settings NoteSettings, source string, err error) {
+	communityEnabled := isCommunityPluginEnabled(vaultPath, "unique-note-creator")
+	if communityEnabled {
+		if format, err := loadUniqueNoteCreatorFormat(vaultPath); err != nil || format != "" {
+			return NoteSettings{Format: format}, uniqueNoteCreatorSettings, err
+		}
 	}
 
-	path := filepath.Join(vaultPath, ".obsidian", "plugins", "unique-note-creator", "data.json")
-	data, err := os.ReadFile(path)
+	if isCorePluginEnabled(vaultPath, "zk-prefixer") {
+		settings, err := loadNoteSettings(filepath.Join(vaultPath, zkPrefixerSettings))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return NoteSettings{}, "", err
+		}
+		if settings.Format == "" {
+			settings.Format = zkPrefixerDefault
+		}
+		return settings, zkPrefixerSettings, nil
+	}
+
+	if !communityEnabled && pluginDirectoryExists(vaultPath, "unique-note-creator") {
+		format, err := loadUniqueNoteCreatorFormat(vaultPath)
+		return NoteSettings{Format: format}, uniqueNoteCreatorSettings, err
+	}
+	return NoteSettings{}, "", nil
+}
+
+func loadUniqueNoteCreatorFormat(vaultPath string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(vaultPath, uniqueNoteCreatorSettings))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return "", nil
@@ -139,6 +278,78 @@ func detectUniqueNoteCreatorFormat(vaultPath string) (string, error) {
 	return findFormatInJSON(data), nil
 }
 
+// periodicNotes holds the settings of enabled Periodic Notes periods.
+type periodicNotes struct {
+	// DailyEnabled is set when the daily period is enabled, which hands
+	// daily notes over from the core plugin.
+	DailyEnabled bool
+	Daily        NoteSettings
+	Weekly       NoteSettings
+	Monthly      NoteSettings
+	Quarterly    NoteSettings
+	Yearly       NoteSettings
+}
+
+type periodicSetting struct {
+	Enabled bool `json:"enabled"`
+	NoteSettings
+}
+
+// Defaults used by the Periodic Notes plugin when a period is enabled without
+// an explicit format.
+const (
+	periodicDailyDefault     = "YYYY-MM-DD"
+	periodicWeeklyDefault    = "gggg-[W]ww"
+	periodicMonthlyDefault   = "YYYY-MM"
+	periodicQuarterlyDefault = "YYYY-[Q]Q"
+	periodicYearlyDefault    = "YYYY"
+)
+
+func detectPeriodicNotes(vaultPath string) (periodicNotes, error) {
+	var notes periodicNotes
+	if !isCommunityPluginEnabled(vaultPath, "periodic-notes") && !pluginDirectoryExists(vaultPath, "periodic-notes") {
+		return notes, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(vaultPath, periodicNotesSettings))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return notes, nil
+		}
+		return notes, err
+	}
+
+	var payload struct {
+		Daily     *periodicSetting `json:"daily"`
+		Weekly    *periodicSetting `json:"weekly"`
+		Monthly   *periodicSetting `json:"monthly"`
+		Quarterly *periodicSetting `json:"quarterly"`
+		Yearly    *periodicSetting `json:"yearly"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return notes, err
+	}
+
+	notes.DailyEnabled = payload.Daily != nil && payload.Daily.Enabled
+	notes.Daily = periodicNote(payload.Daily, periodicDailyDefault)
+	notes.Weekly = periodicNote(payload.Weekly, periodicWeeklyDefault)
+	notes.Monthly = periodicNote(payload.Monthly, periodicMonthlyDefault)
+	notes.Quarterly = periodicNote(payload.Quarterly, periodicQuarterlyDefault)
+	notes.Yearly = periodicNote(payload.Yearly, periodicYearlyDefault)
+	return notes, nil
+}
+
+func periodicNote(setting *periodicSetting, fallback string) NoteSettings {
+	if setting == nil || !setting.Enabled {
+		return NoteSettings{}
+	}
+	note := setting.NoteSettings
+	if note.Format == "" {
+		note.Format = fallback
+	}
+	return note
+}
+
 func isCorePluginEnabled(vaultPath, pluginID string) bool {
 	ids, err := loadPluginList(filepath.Join(vaultPath, ".obsidian", "core-plugins.json"))
 	if err != nil {
@@ -181,40 +392,51 @@ func loadPluginList(path string) ([]string, error) {
 	}
 
 	var ids []string
-	if err := json.Unmarshal(data, &ids); err != nil {
+	if err := json.Unmarshal(data, &ids); err == nil {
+		return ids, nil
+	}
+
+	// Recent Obsidian versions write core-plugins.json as an object of
+	// plugin IDs to their enabled state.
+	var enabled map[string]bool
+	if err := json.Unmarshal(data, &enabled); err != nil {
 		return nil, err
 	}
+	for id, on := range enabled {
+		if on {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
 	return ids, nil
 }
 
-func loadDailyNotesJSON(path string) (string, error) {
+// loadNoteSettings reads a core plugin's settings file, such as
+// daily-notes.json.
+func loadNoteSettings(path string) (NoteSettings, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return NoteSettings{}, err
 	}
-	var payload struct {
-		Format string `json:"format"`
+	var settings NoteSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return NoteSettings{}, err
 	}
-	if err := json.Unmarshal(data, &payload); err != nil {
-		return "", err
-	}
-	return payload.Format, nil
+	return settings, nil
 }
 
-func loadDailyNotesFromApp(path string) (string, error) {
+func loadDailyNotesFromApp(path string) (NoteSettings, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return NoteSettings{}, err
 	}
 	var payload struct {
-		DailyNotes struct {
-			Format string `json:"format"`
-		} `json:"dailyNotes"`
+		DailyNotes NoteSettings `json:"dailyNotes"`
 	}
 	if err := json.Unmarshal(data, &payload); err != nil {
-		return "", err
+		return NoteSettings{}, err
 	}
-	return payload.DailyNotes.Format, nil
+	return payload.DailyNotes, nil
 }
 
 func findFormatInJSON(data []byte) string {
@@ -232,15 +454,21 @@ func findFormatInJSON(data []byte) string {
 func searchFormat(node interface{}) (string, bool) {
 	switch v := node.(type) {
 	case map[string]interface{}:
-		for key, val := range v {
+		// Sorted keys keep the choice stable when several strings qualify.
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
 			if isFormatKey(key) {
-				if str, ok := val.(string); ok && looksLikeMomentFormat(str) {
+				if str, ok := v[key].(string); ok && looksLikeMomentFormat(str) {
 					return str, true
 				}
 			}
 		}
-		for _, val := range v {
-			if str, ok := searchFormat(val); ok {
+		for _, key := range keys {
+			if str, ok := searchFormat(v[key]); ok {
 				return str, true
 			}
 		}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDetectNoVault(t *testing.T) {
@@ -29,7 +30,7 @@ func TestDetectWithPlugins(t *testing.T) {
 	}
 
 	writeJSON(t, filepath.Join(obsidianDir, "core-plugins.json"), `["daily-notes"]`)
-	writeJSON(t, filepath.Join(obsidianDir, "daily-notes.json"), `{"format":"YYYY-MM-DD"}`)
+	writeJSON(t, filepath.Join(obsidianDir, "daily-notes.json"), `{"format":"YYYY-MM-DD","folder":"Journal","template":"Templates/Daily"}`)
 
 	writeJSON(t, filepath.Join(obsidianDir, "community-plugins.json"), `["unique-note-creator"]`)
 	writeJSON(t, filepath.Join(pluginsDir, "data.json"), `{"filenameFormat":"YYYYMMDDHHmm"}`)
@@ -43,11 +44,97 @@ func TestDetectWithPlugins(t *testing.T) {
 		t.Fatalf("expected to be inside vault")
 	}
 
-	if result.Layouts.Daily != "2006-01-02" {
-		t.Fatalf("unexpected daily layout: %q", result.Layouts.Daily)
+	if result.Layouts.DailyLayout != "2006-01-02" {
+		t.Fatalf("unexpected daily layout: %q", result.Layouts.DailyLayout)
+	}
+	if result.Layouts.DefaultLayout != "200601021504" {
+		t.Fatalf("unexpected default layout: %q", result.Layouts.DefaultLayout)
+	}
+
+	moment := time.Date(2025, 11, 12, 15, 34, 0, 0, time.UTC)
+	if got := result.Layouts.Daily(moment); got != "2025-11-12" {
+		t.Fatalf("unexpected daily output: %q", got)
+	}
+	if got := result.Layouts.Default(moment); got != "202511121534" {
+		t.Fatalf("unexpected default output: %q", got)
+	}
+
+	daily := NoteSettings{Format: "YYYY-MM-DD", Folder: "Journal", Template: "Templates/Daily"}
+	if result.Notes["daily"] != daily {
+		t.Fatalf("unexpected daily settings: %+v", result.Notes["daily"])
+	}
+}
+
+func TestDetectWithZkPrefixer(t *testing.T) {
+	dir := t.TempDir()
+	vault := filepath.Join(dir, "vault")
+	obsidianDir := filepath.Join(vault, ".obsidian")
+
+	writeJSON(t, filepath.Join(obsidianDir, "core-plugins.json"), `["daily-notes", "zk-prefixer"]`)
+	writeJSON(t, filepath.Join(obsidianDir, "daily-notes.json"), `{"format":"YYYY-MM-DD"}`)
+	writeJSON(t, filepath.Join(obsidianDir, "zk-prefixer.json"), `{"format":"YYYYMMDDHHmmss","folder":"Zettelkasten","template":"Templates/Zettel"}`)
+
+	result, err := Detect(filepath.Join(vault, "notes"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !result.InVault {
+		t.Fatalf("expected to be inside vault")
+	}
+
+	if result.Layouts.DailyLayout != "2006-01-02" {
+		t.Fatalf("unexpected daily layout: %q", result.Layouts.DailyLayout)
 	}
-	if result.Layouts.Default != "200601021504" {
-		t.Fatalf("unexpected default layout: %q", result.Layouts.Default)
+	if result.Layouts.DefaultLayout != "20060102150405" {
+		t.Fatalf("unexpected default layout: %q", result.Layouts.DefaultLayout)
+	}
+	want := NoteSettings{Format: "YYYYMMDDHHmmss", Folder: "Zettelkasten", Template: "Templates/Zettel"}
+	if result.Notes["default"] != want {
+		t.Fatalf("unexpected default settings: %+v", result.Notes["default"])
+	}
+}
+
+func TestDetectUniqueNotePrecedence(t *testing.T) {
+	tests := []struct {
+		name      string
+		core      string // core-plugins.json, if any
+		community bool   // unique-note-creator enabled, not just installed
+		zkFile    string
+		layout    string
+	}{
+		{"community plugin beats core plugin", `["zk-prefixer"]`, true, `{"format":"YYYYMMDDHHmmss"}`, "0601021504"},
+		{"core plugin beats installed community plugin", `["zk-prefixer"]`, false, `{"format":"YYYYMMDDHHmmss"}`, "20060102150405"},
+		{"installed community plugin alone", "", false, `{"format":"YYYYMMDDHHmmss"}`, "0601021504"},
+		{"core plugin default format", `["zk-prefixer"]`, false, "", "200601021504"},
+		{"core plugin enabled in object form", `{"daily-notes": true, "zk-prefixer": true}`, false, `{"format":"YYYYMMDDHHmmss"}`, "20060102150405"},
+		{"core plugin disabled in object form", `{"daily-notes": true, "zk-prefixer": false}`, false, `{"format":"YYYYMMDDHHmmss"}`, "0601021504"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			obsidianDir := filepath.Join(dir, ".obsidian")
+
+			writeJSON(t, filepath.Join(obsidianDir, "plugins", "unique-note-creator", "data.json"), `{"filenameFormat":"YYMMDDHHmm"}`)
+			if tt.core != "" {
+				writeJSON(t, filepath.Join(obsidianDir, "core-plugins.json"), tt.core)
+			}
+			if tt.community {
+				writeJSON(t, filepath.Join(obsidianDir, "community-plugins.json"), `["unique-note-creator"]`)
+			}
+			if tt.zkFile != "" {
+				writeJSON(t, filepath.Join(obsidianDir, "zk-prefixer.json"), tt.zkFile)
+			}
+
+			result, err := Detect(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Layouts.DefaultLayout != tt.layout {
+				t.Fatalf("default layout = %q, want %q", result.Layouts.DefaultLayout, tt.layout)
+			}
+		})
 	}
 }
 
@@ -60,3 +147,162 @@ func writeJSON(t *testing.T, path, payload string) {
 		t.Fatalf("write error: %v", err)
 	}
 }
+
+func TestDetectPeriodicNotes(t *testing.T) {
+	dir := t.TempDir()
+	obsidianDir := filepath.Join(dir, ".obsidian")
+	pluginDir := filepath.Join(obsidianDir, "plugins", "periodic-notes")
+
+	writeJSON(t, filepath.Join(obsidianDir, "community-plugins.json"), `["periodic-notes"]`)
+	writeJSON(t, filepath.Join(pluginDir, "data.json"), `{
+		"daily": {"enabled": true, "format": "YYYY-MM-DD ddd", "folder": "Journal/Daily"},
+		"weekly": {"enabled": true, "format": "GGGG-[W]WW"},
+		"monthly": {"enabled": true, "format": "YYYY-MM MMMM"},
+		"quarterly": {"enabled": true, "format": ""},
+		"yearly": {"enabled": true, "format": "[Year] YYYY"}
+	}`)
+
+	result, err := Detect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	moment := time.Date(2025, 12, 29, 9, 0, 0, 0, time.UTC)
+	if result.Layouts.Weekly == nil {
+		t.Fatal("expected weekly renderer")
+	}
+	if got := result.Layouts.Weekly(moment); got != "2026-W01" {
+		t.Fatalf("unexpected weekly output: %q", got)
+	}
+	if result.Layouts.Quarterly == nil {
+		t.Fatal("expected quarterly renderer from plugin default")
+	}
+	if got := result.Layouts.Quarterly(moment); got != "2025-Q4" {
+		t.Fatalf("unexpected quarterly output: %q", got)
+	}
+
+	renderers := map[string]func(time.Time) string{
+		"daily":   result.Layouts.Daily,
+		"monthly": result.Layouts.Monthly,
+		"yearly":  result.Layouts.Yearly,
+	}
+	want := map[string]string{
+		"daily":   "2025-12-29 Mon",
+		"monthly": "2025-12 December",
+		"yearly":  "Year 2025",
+	}
+	for note, render := range renderers {
+		if render == nil {
+			t.Fatalf("expected %s renderer", note)
+		}
+		if got := render(moment); got != want[note] {
+			t.Fatalf("unexpected %s output: %q, want %q", note, got, want[note])
+		}
+	}
+	if result.Layouts.DailyLayout != "2006-01-02 Mon" || result.Layouts.MonthlyLayout != "2006-01 January" || result.Layouts.YearlyLayout != "Year 2006" {
+		t.Fatalf("unexpected layouts: %+v", result.Layouts)
+	}
+	if got := result.Notes["daily"].Folder; got != "Journal/Daily" {
+		t.Fatalf("unexpected daily folder: %q", got)
+	}
+	if _, ok := result.Notes["quarterly"]; !ok {
+		t.Fatal("expected settings for the enabled quarterly period")
+	}
+}
+
+func TestDetectPeriodicNotesDailyPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	obsidianDir := filepath.Join(dir, ".obsidian")
+	pluginDir := filepath.Join(obsidianDir, "plugins", "periodic-notes")
+
+	writeJSON(t, filepath.Join(obsidianDir, "core-plugins.json"), `["daily-notes"]`)
+	writeJSON(t, filepath.Join(obsidianDir, "daily-notes.json"), `{"format":"DD.MM.YYYY"}`)
+	writeJSON(t, filepath.Join(pluginDir, "data.json"), `{"daily": {"enabled": false, "format": "YYYYMMDD"}}`)
+
+	result, err := Detect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Layouts.DailyLayout != "02.01.2006" {
+		t.Fatalf("expected the core Daily Notes format while the periodic daily is disabled, got %q", result.Layouts.DailyLayout)
+	}
+
+	writeJSON(t, filepath.Join(pluginDir, "data.json"), `{"daily": {"enabled": true, "format": ""}}`)
+	result, err = Detect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Layouts.DailyLayout != "2006-01-02" {
+		t.Fatalf("expected the Periodic Notes daily default to win, got %q", result.Layouts.DailyLayout)
+	}
+}
+
+func TestDetectPeriodicNotesDisabledPeriod(t *testing.T) {
+	dir := t.TempDir()
+	pluginDir := filepath.Join(dir, ".obsidian", "plugins", "periodic-notes")
+
+	writeJSON(t, filepath.Join(pluginDir, "data.json"), `{"weekly": {"enabled": false, "format": "gggg-ww"}}`)
+
+	result, err := Detect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Layouts.Weekly != nil || result.Layouts.Monthly != nil || result.Layouts.Quarterly != nil || result.Layouts.Yearly != nil {
+		t.Fatal("expected no periodic renderers for disabled periods")
+	}
+}
+
+func TestDetectStrict(t *testing.T) {
+	dir := t.TempDir()
+	obsidianDir := filepath.Join(dir, ".obsidian")
+	pluginDir := filepath.Join(obsidianDir, "plugins", "unique-note-creator")
+
+	writeJSON(t, filepath.Join(obsidianDir, "core-plugins.json"), `["daily-notes"]`)
+	writeJSON(t, filepath.Join(obsidianDir, "daily-notes.json"), `{"format":"2006-01-02"}`)
+	writeJSON(t, filepath.Join(pluginDir, "data.json"), `{"format":"YYYYMMDDHHmm"}`)
+
+	lenient, err := Detect(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lenient.Layouts.Daily == nil || len(lenient.Diagnostics) != 0 {
+		t.Fatalf("expected the lenient mode to apply the format silently, got %v", lenient.Diagnostics)
+	}
+
+	strict, err := DetectWith(dir, Options{Strict: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strict.Layouts.Daily != nil || strict.Layouts.DailyLayout != "" {
+		t.Fatal("expected the rejected daily format to leave the built-in layout")
+	}
+	if strict.Layouts.Default == nil {
+		t.Fatal("expected the valid default format to apply")
+	}
+	if len(strict.Diagnostics) == 0 {
+		t.Fatal("expected diagnostics for the daily format")
+	}
+	diag := strict.Diagnostics[0]
+	if diag.Note != "daily" || diag.Source != filepath.Join(".obsidian", "daily-notes.json") || diag.Column != 1 {
+		t.Fatalf("unexpected diagnostic: %+v", diag)
+	}
+}
+
+func TestDetectStrictWarning(t *testing.T) {
+	dir := t.TempDir()
+	obsidianDir := filepath.Join(dir, ".obsidian")
+
+	writeJSON(t, filepath.Join(obsidianDir, "core-plugins.json"), `["daily-notes"]`)
+	writeJSON(t, filepath.Join(obsidianDir, "daily-notes.json"), `{"format":"YYYY-MM-DDTHH"}`)
+
+	result, err := DetectWith(dir, Options{Strict: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Diagnostics) != 1 || !result.Diagnostics[0].Warning {
+		t.Fatalf("expected a single warning, got %+v", result.Diagnostics)
+	}
+	if result.Layouts.DailyLayout != "2006-01-02T15" {
+		t.Fatalf("daily layout = %q, want the vault format kept", result.Layouts.DailyLayout)
+	}
+}
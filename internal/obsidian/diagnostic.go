@@ -0,0 +1,160 @@
+package obsidian
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagnostic reports a problem with a format found in the vault.
+type Diagnostic struct {
+	// Note is the kind of note the format names: default, daily, weekly,
+	// monthly, quarterly or yearly.
+	Note string
+	// Source is the settings file the format was read from, relative to the
+	// vault.
+	Source string
+	Format string
+	// Column is the 1-based position of the problem in Format, in runes.
+	Column  int
+	Message string
+	// Warning marks problems that leave the format usable, such as the
+	// unbracketed T of YYYY-MM-DDTHH. Strict mode still applies the format.
+	Warning bool
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s format %q, column %d: %s", d.Source, d.Note, d.Format, d.Column, d.Message)
+}
+
+// goLayoutValues maps the values of Go's reference time, which show up when a
+// Go layout is pasted where a Moment format belongs, to their Moment tokens.
+var goLayoutValues = map[string]string{
+	"01": "MM",
+	"02": "DD",
+	"03": "hh",
+	"04": "mm",
+	"05": "ss",
+	"06": "YY",
+	"15": "HH",
+}
+
+// checkFormat reports what Moment and stamp would not render as intended:
+// unbalanced brackets, era tokens, unbracketed words that mix literal letters
+// with tokens (in "note", "e" is the day of the week), Go layout digits such
+// as 2006 in literal text, and formats without any tokens. A single capital
+// letter between tokens, as in "DDTHH", is only a warning.
+func checkFormat(format string) []Diagnostic {
+	var diags []Diagnostic
+	report := func(offset int, message string, args ...any) {
+		diags = append(diags, Diagnostic{Format: format, Column: offset + 1, Message: fmt.Sprintf(message, args...)})
+	}
+	warn := func(offset int, message string, args ...any) {
+		report(offset, message, args...)
+		diags[len(diags)-1].Warning = true
+	}
+
+	runes := []rune(format)
+	sawToken := false
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case r == '[':
+			j := i + 1
+			for j < len(runes) && runes[j] != ']' {
+				j++
+			}
+			if j >= len(runes) {
+				report(i, "unbalanced [; close literal text with ]")
+				return diags
+			}
+			i = j + 1
+		case r == '\\':
+			i += 2
+		case isASCIILetter(r):
+			j := i
+			for j < len(runes) && isASCIILetter(runes[j]) {
+				j++
+			}
+			tokens, literals := splitWord(runes[i:j])
+			sawToken = sawToken || len(tokens) > 0
+			word := string(runes[i:j])
+			switch {
+			case strings.ContainsAny(literals, "Ny"):
+				report(i, "%q uses era tokens (N, y), which are not supported", word)
+			case isSeparatorLetter(word, literals):
+				warn(i, "%q has an unbracketed literal %s between tokens; write [%s] to make it explicit", word, literals, literals)
+			case literals != "" && len(tokens) > 0:
+				report(i, "%q mixes tokens (%s) with literal letters (%s); wrap literal text in [brackets]",
+					word, strings.Join(tokens, ", "), strings.Join(strings.Split(literals, ""), ", "))
+			case literals != "":
+				report(i, "%q is not a Moment token; wrap literal text in [brackets]", word)
+			}
+			i = j
+		case r >= '0' && r <= '9':
+			j := i
+			for j < len(runes) && runes[j] >= '0' && runes[j] <= '9' {
+				j++
+			}
+			digits := string(runes[i:j])
+			if token, ok := goLayoutValues[digits]; ok {
+				report(i, "%q looks like a Go time layout; use the Moment token %s", digits, token)
+			} else if strings.Contains(digits, "2006") || isGoLayoutRun(digits) {
+				report(i, "%q looks like a Go time layout; use Moment tokens such as YYYY, MM and DD", digits)
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+
+	if !sawToken {
+		report(0, "no Moment tokens, so every note would get the same name")
+	}
+	return diags
+}
+
+// isSeparatorLetter reports whether the only literal of word is one capital
+// letter between tokens, as in "DDTHH", which renders as written.
+func isSeparatorLetter(word, literals string) bool {
+	if len(literals) != 1 || literals[0] < 'A' || literals[0] > 'Z' {
+		return false
+	}
+	return word[0] != literals[0] && word[len(word)-1] != literals[0]
+}
+
+// splitWord tokenizes a run of letters the way parseMoment does, returning
+// the tokens and the letters that are copied literally.
+func splitWord(word []rune) (tokens []string, literals string) {
+	for i := 0; i < len(word); {
+		if name, _, ok := matchLocalized(word, i); ok {
+			tokens = append(tokens, name)
+			i += len(name)
+			continue
+		}
+		if token, ok := matchToken(word, i); ok {
+			tokens = append(tokens, token.token)
+			i += len(token.token)
+			continue
+		}
+		literals += string(word[i])
+		i++
+	}
+	return tokens, literals
+}
+
+// isGoLayoutRun reports whether digits consists of Go reference time values,
+// such as 1504.
+func isGoLayoutRun(digits string) bool {
+	if len(digits) < 4 || len(digits)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(digits); i += 2 {
+		if _, ok := goLayoutValues[digits[i:i+2]]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCIILetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
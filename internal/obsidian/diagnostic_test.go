@@ -0,0 +1,55 @@
+package obsidian
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		column  int
+		want    string // substring of the message, empty for a clean format
+		warning bool
+	}{
+		{"YYYY-MM-DD", 0, "", false},
+		{"YYYYMMDDHHmmss", 0, "", false},
+		{"[Daily] dddd, Do MMMM YYYY", 0, "", false},
+		{"gggg-[W]ww", 0, "", false},
+		{"YYYY-MM-DD[T]HH\\:mm", 0, "", false},
+		{"YYYY-MM-DDTHH", 9, `"DDTHH" has an unbracketed literal T between tokens`, true},
+		{"YYYY-MM-DDTHHmm", 9, `"DDTHHmm" has an unbracketed literal T`, true},
+		{"YYYY-MM-DDTTHH", 9, `"DDTTHH" mixes tokens (DD, HH) with literal letters (T, T)`, false},
+		{"YYYY-MM-DD-Tmm", 12, `"Tmm" mixes tokens (mm) with literal letters (T)`, false},
+		{"YYYY-MM-DD-note", 12, `"note" mixes tokens (e) with literal letters (n, o, t)`, false},
+		{"[note]-YYYY-Jan", 13, `"Jan" mixes tokens (a)`, false},
+		{"YYYY-MM-DD-T", 12, `"T" is not a Moment token`, false},
+		{"NNNN YYYY", 1, "era tokens", false},
+		{"2006-01-02", 1, `"2006" looks like a Go time layout`, false},
+		{"YYYY-01", 6, `use the Moment token MM`, false},
+		{"YYYYMMDD-1504", 10, `"1504" looks like a Go time layout`, false},
+		{"[Inbox]", 1, "no Moment tokens", false},
+		{"YYYY-[MM", 6, "unbalanced [", false},
+	}
+
+	for _, tt := range tests {
+		diags := checkFormat(tt.format)
+		if tt.want == "" {
+			if len(diags) != 0 {
+				t.Errorf("checkFormat(%q) = %v, want no diagnostics", tt.format, diags)
+			}
+			continue
+		}
+		if len(diags) == 0 {
+			t.Errorf("checkFormat(%q) found nothing, want %q", tt.format, tt.want)
+			continue
+		}
+		if !strings.Contains(diags[0].Message, tt.want) || diags[0].Column != tt.column {
+			t.Errorf("checkFormat(%q)[0] = column %d %q, want column %d %q",
+				tt.format, diags[0].Column, diags[0].Message, tt.column, tt.want)
+		}
+		if diags[0].Warning != tt.warning {
+			t.Errorf("checkFormat(%q)[0].Warning = %v, want %v", tt.format, diags[0].Warning, tt.warning)
+		}
+	}
+}
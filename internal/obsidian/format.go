@@ -1,44 +1,191 @@
 package obsidian
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/toto/stamp/internal/calendar"
 )
 
-var tokenMap = []struct {
+// momentToken is one Moment.js formatting token. Layout is the equivalent Go
+// time layout, empty when Go has none (ordinals, week numbers, unpadded
+// 24-hour clocks and the like).
+type momentToken struct {
 	token  string
 	layout string
-}{
-	{"YYYY", "2006"},
-	{"YY", "06"},
-	{"MMMM", "January"},
-	{"MMM", "Jan"},
-	{"MM", "01"},
-	{"M", "1"},
-	{"DDDD", "Monday"},
-	{"DDD", "Mon"},
-	{"DD", "02"},
-	{"D", "2"},
-	{"HH", "15"},
-	{"H", "15"},
-	{"hh", "03"},
-	{"h", "3"},
-	{"mm", "04"},
-	{"m", "4"},
-	{"ss", "05"},
-	{"s", "5"},
-	{"ZZ", "-0700"},
-	{"A", "PM"},
-	{"a", "pm"},
-	{"Z", "-0700"},
-	{"T", "T"},
-}
-
-// momentToGoLayout converts a subset of Moment.js style tokens used by Obsidian
-// into Go time layouts. Returns false when conversion fails.
+	render func(time.Time) string
+}
+
+// momentTokens covers Moment's formatting grammar for its default "en" locale,
+// except eras. Locale weeks start on Sunday and week 1 contains January 1st;
+// ISO weeks start on Monday.
+var momentTokens = []momentToken{
+	{"M", "1", func(t time.Time) string { return strconv.Itoa(int(t.Month())) }},
+	{"Mo", "", func(t time.Time) string { return ordinal(int(t.Month())) }},
+	{"MM", "01", func(t time.Time) string { return pad(int(t.Month()), 2) }},
+	{"MMM", "Jan", func(t time.Time) string { return t.Month().String()[:3] }},
+	{"MMMM", "January", func(t time.Time) string { return t.Month().String() }},
+
+	{"Q", "", func(t time.Time) string { return strconv.Itoa(calendar.Quarter(t)) }},
+	{"Qo", "", func(t time.Time) string { return ordinal(calendar.Quarter(t)) }},
+
+	{"D", "2", func(t time.Time) string { return strconv.Itoa(t.Day()) }},
+	{"Do", "", func(t time.Time) string { return ordinal(t.Day()) }},
+	{"DD", "02", func(t time.Time) string { return pad(t.Day(), 2) }},
+	{"DDD", "", func(t time.Time) string { return strconv.Itoa(t.YearDay()) }},
+	{"DDDo", "", func(t time.Time) string { return ordinal(t.YearDay()) }},
+	{"DDDD", "002", func(t time.Time) string { return pad(t.YearDay(), 3) }},
+
+	{"d", "", func(t time.Time) string { return strconv.Itoa(int(t.Weekday())) }},
+	{"do", "", func(t time.Time) string { return ordinal(int(t.Weekday())) }},
+	{"dd", "", func(t time.Time) string { return t.Weekday().String()[:2] }},
+	{"ddd", "Mon", func(t time.Time) string { return t.Weekday().String()[:3] }},
+	{"dddd", "Monday", func(t time.Time) string { return t.Weekday().String() }},
+	{"e", "", func(t time.Time) string { return strconv.Itoa(int(t.Weekday())) }},
+	{"E", "", func(t time.Time) string { return strconv.Itoa(isoWeekday(t)) }},
+
+	{"w", "", func(t time.Time) string { return strconv.Itoa(localeWeek(t)) }},
+	{"wo", "", func(t time.Time) string { return ordinal(localeWeek(t)) }},
+	{"ww", "", func(t time.Time) string { return pad(localeWeek(t), 2) }},
+	{"W", "", func(t time.Time) string { return strconv.Itoa(isoWeek(t)) }},
+	{"Wo", "", func(t time.Time) string { return ordinal(isoWeek(t)) }},
+	{"WW", "", func(t time.Time) string { return pad(isoWeek(t), 2) }},
+
+	{"Y", "2006", func(t time.Time) string {
+		if t.Year() > 9999 {
+			return "+" + strconv.Itoa(t.Year())
+		}
+		return pad(t.Year(), 4)
+	}},
+	{"YY", "06", func(t time.Time) string { return pad(t.Year()%100, 2) }},
+	{"YYYY", "2006", func(t time.Time) string { return pad(t.Year(), 4) }},
+	{"YYYYY", "", func(t time.Time) string { return pad(t.Year(), 5) }},
+	{"YYYYYY", "", func(t time.Time) string { return signed(t.Year(), 6) }},
+	{"gg", "", func(t time.Time) string { return pad(localeWeekYear(t)%100, 2) }},
+	{"gggg", "", func(t time.Time) string { return pad(localeWeekYear(t), 4) }},
+	{"ggggg", "", func(t time.Time) string { return pad(localeWeekYear(t), 5) }},
+	{"GG", "", func(t time.Time) string { return pad(isoWeekYear(t)%100, 2) }},
+	{"GGGG", "", func(t time.Time) string { return pad(isoWeekYear(t), 4) }},
+	{"GGGGG", "", func(t time.Time) string { return pad(isoWeekYear(t), 5) }},
+
+	{"A", "PM", func(t time.Time) string { return meridiem(t) }},
+	{"a", "pm", func(t time.Time) string { return strings.ToLower(meridiem(t)) }},
+
+	{"H", "", func(t time.Time) string { return strconv.Itoa(t.Hour()) }},
+	{"HH", "15", func(t time.Time) string { return pad(t.Hour(), 2) }},
+	{"h", "3", func(t time.Time) string { return strconv.Itoa(hour12(t)) }},
+	{"hh", "03", func(t time.Time) string { return pad(hour12(t), 2) }},
+	{"k", "", func(t time.Time) string { return strconv.Itoa(hour24(t)) }},
+	{"kk", "", func(t time.Time) string { return pad(hour24(t), 2) }},
+	{"m", "4", func(t time.Time) string { return strconv.Itoa(t.Minute()) }},
+	{"mm", "04", func(t time.Time) string { return pad(t.Minute(), 2) }},
+	{"s", "5", func(t time.Time) string { return strconv.Itoa(t.Second()) }},
+	{"ss", "05", func(t time.Time) string { return pad(t.Second(), 2) }},
+	fraction(1), fraction(2), fraction(3), fraction(4), fraction(5),
+	fraction(6), fraction(7), fraction(8), fraction(9),
+
+	// Without moment-timezone, Moment only names the UTC zone.
+	{"z", "", zoneName},
+	{"zz", "", zoneName},
+	{"Z", "-07:00", func(t time.Time) string { return t.Format("-07:00") }},
+	{"ZZ", "-0700", func(t time.Time) string { return t.Format("-0700") }},
+
+	{"X", "", func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) }},
+	{"x", "", func(t time.Time) string { return strconv.FormatInt(t.UnixMilli(), 10) }},
+}
+
+// localizedFormats are the "en" locale's long date formats.
+var localizedFormats = map[string]string{
+	"LT":   "h:mm A",
+	"LTS":  "h:mm:ss A",
+	"L":    "MM/DD/YYYY",
+	"LL":   "MMMM D, YYYY",
+	"LLL":  "MMMM D, YYYY h:mm A",
+	"LLLL": "dddd, MMMM D, YYYY h:mm A",
+	"l":    "M/D/YYYY",
+	"ll":   "MMM D, YYYY",
+	"lll":  "MMM D, YYYY h:mm A",
+	"llll": "ddd, MMM D, YYYY h:mm A",
+}
+
+// layoutSamples are the moments a Go layout must render exactly like the
+// Moment format it was derived from. Between them every field has both one
+// and two digits, and the clock is on both sides of noon.
+var layoutSamples = []time.Time{
+	time.Date(2009, time.November, 17, 20, 34, 58, 651387237, time.UTC),
+	time.Date(2024, time.February, 5, 7, 8, 9, 42000000, time.FixedZone("", 5*3600+30*60)),
+}
+
+// CompileFormat converts a Moment.js style format into a renderer. It exposes
+// the Obsidian translator to formats declared outside a vault, such as
+// user-defined note types.
+func CompileFormat(format string) (func(time.Time) string, error) {
+	render, ok := momentFormatter(format)
+	if !ok {
+		return nil, fmt.Errorf("invalid format %q: unbalanced literal brackets", format)
+	}
+	return render, nil
+}
+
+// momentFormatter converts a Moment.js style format into a renderer. Returns
+// false when the format cannot be parsed.
+func momentFormatter(format string) (func(time.Time) string, bool) {
+	tokens, ok := parseMoment(format)
+	if !ok {
+		return nil, false
+	}
+	return func(t time.Time) string {
+		var out strings.Builder
+		for _, token := range tokens {
+			out.WriteString(token.render(t))
+		}
+		return out.String()
+	}, true
+}
+
+// momentToGoLayout converts a Moment.js style format into the equivalent Go
+// time layout, for parsing names. Returns false when Go has no equivalent,
+// either for a token or because literal text would read as a layout element.
 func momentToGoLayout(format string) (string, bool) {
+	tokens, ok := parseMoment(format)
+	if !ok {
+		return "", false
+	}
+
 	var builder strings.Builder
-	runes := []rune(format)
+	for _, token := range tokens {
+		if token.layout == "" {
+			return "", false
+		}
+		builder.WriteString(token.layout)
+	}
+	layout := builder.String()
 
+	render, _ := momentFormatter(format)
+	for _, sample := range layoutSamples {
+		if sample.Format(layout) != render(sample) {
+			return "", false
+		}
+	}
+	return layout, true
+}
+
+// parseMoment splits format into tokens, turning [bracketed] text,
+// backslash-escaped characters and anything that is not a token into
+// literals. Returns false for an unbalanced bracket.
+func parseMoment(format string) ([]momentToken, bool) {
+	var tokens []momentToken
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			tokens = append(tokens, literalToken(literal.String()))
+			literal.Reset()
+		}
+	}
+
+	runes := []rune(format)
 	for i := 0; i < len(runes); {
 		switch runes[i] {
 		case '[':
@@ -47,61 +194,171 @@ func momentToGoLayout(format string) (string, bool) {
 				j++
 			}
 			if j >= len(runes) {
-				return "", false
+				return nil, false
 			}
-			builder.WriteString(string(runes[i+1 : j]))
+			literal.WriteString(string(runes[i+1 : j]))
 			i = j + 1
 			continue
 		case '\\':
-			// Escape next rune literally
 			if i+1 < len(runes) {
-				builder.WriteRune(runes[i+1])
+				literal.WriteRune(runes[i+1])
 				i += 2
 			} else {
-				builder.WriteRune(runes[i])
+				literal.WriteRune(runes[i])
 				i++
 			}
 			continue
 		}
 
-		if token, layout, ok := matchToken(runes, i); ok {
-			builder.WriteString(layout)
-			i += len(token)
+		if name, expansion, ok := matchLocalized(runes, i); ok {
+			expanded, _ := parseMoment(expansion)
+			flush()
+			tokens = append(tokens, expanded...)
+			i += len(name)
+			continue
+		}
+
+		if token, ok := matchToken(runes, i); ok {
+			flush()
+			tokens = append(tokens, token)
+			i += len(token.token)
 			continue
 		}
 
-		// Unknown token, write rune verbatim
-		builder.WriteRune(runes[i])
+		literal.WriteRune(runes[i])
 		i++
 	}
+	flush()
+	return tokens, true
+}
 
-	return builder.String(), true
+func literalToken(text string) momentToken {
+	return momentToken{layout: text, render: func(time.Time) string { return text }}
 }
 
-func matchToken(runes []rune, start int) (token, layout string, ok bool) {
-	for _, entry := range tokenMap {
-		tokenRunes := []rune(entry.token)
-		if len(runes)-start < len(tokenRunes) {
-			continue
+// matchToken returns the longest token at start, as Moment does.
+func matchToken(runes []rune, start int) (match momentToken, ok bool) {
+	for _, entry := range momentTokens {
+		if len(entry.token) > len(match.token) && hasPrefixAt(runes, start, entry.token) {
+			match, ok = entry, true
 		}
-		if equalRunes(runes[start:start+len(tokenRunes)], tokenRunes) {
-			token = entry.token
-			layout = entry.layout
-			ok = true
-			return
+	}
+	return match, ok
+}
+
+func matchLocalized(runes []rune, start int) (name, expansion string, ok bool) {
+	for token, format := range localizedFormats {
+		if len(token) > len(name) && hasPrefixAt(runes, start, token) {
+			name, expansion, ok = token, format, true
 		}
 	}
-	return
+	return name, expansion, ok
 }
 
-func equalRunes(a, b []rune) bool {
-	if len(a) != len(b) {
+func hasPrefixAt(runes []rune, start int, token string) bool {
+	tokenRunes := []rune(token)
+	if len(runes)-start < len(tokenRunes) {
 		return false
 	}
-	for i := range a {
-		if a[i] != b[i] {
+	for i, r := range tokenRunes {
+		if runes[start+i] != r {
 			return false
 		}
 	}
 	return true
 }
+
+// fraction renders n digits of the second's fraction. Moment keeps
+// milliseconds, so digits past the third are always zero.
+func fraction(n int) momentToken {
+	return momentToken{
+		token:  strings.Repeat("S", n),
+		layout: strings.Repeat("0", n),
+		render: func(t time.Time) string {
+			digits := pad(t.Nanosecond()/int(time.Millisecond), 3) + "000000"
+			return digits[:n]
+		},
+	}
+}
+
+// ordinal renders n the way Moment's "en" locale does: 1st, 2nd, 11th, 23rd.
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100/10 != 1 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func pad(n, width int) string {
+	return fmt.Sprintf("%0*d", width, n)
+}
+
+func signed(n, width int) string {
+	if n < 0 {
+		return "-" + pad(-n, width)
+	}
+	return "+" + pad(n, width)
+}
+
+func meridiem(t time.Time) string {
+	if t.Hour() < 12 {
+		return "AM"
+	}
+	return "PM"
+}
+
+func hour12(t time.Time) int {
+	if h := t.Hour() % 12; h != 0 {
+		return h
+	}
+	return 12
+}
+
+func hour24(t time.Time) int {
+	if t.Hour() == 0 {
+		return 24
+	}
+	return t.Hour()
+}
+
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+func zoneName(t time.Time) string {
+	if t.Location() == time.UTC {
+		return "UTC"
+	}
+	return ""
+}
+
+func isoWeek(t time.Time) int {
+	_, week := calendar.ISOWeek(t)
+	return week
+}
+
+func isoWeekYear(t time.Time) int {
+	year, _ := calendar.ISOWeek(t)
+	return year
+}
+
+func localeWeek(t time.Time) int {
+	_, week := calendar.LocaleWeek(t, 0, 6)
+	return week
+}
+
+func localeWeekYear(t time.Time) int {
+	year, _ := calendar.LocaleWeek(t, 0, 6)
+	return year
+}
@@ -1,15 +1,26 @@
 package obsidian
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestMomentToGoLayout(t *testing.T) {
 	tests := map[string]string{
-		"YYYY-MM-DD":     "2006-01-02",
-		"YYYYMMDDHHmm":   "200601021504",
-		"[prefix]-YYYY":  "prefix-2006",
-		"YYYY-MM-DDTHH":  "2006-01-02T15",
-		"YYYY-MM-DD HH":  "2006-01-02 15",
-		"YYYY-MM-DD hhA": "2006-01-02 03PM",
+		"YYYY-MM-DD":              "2006-01-02",
+		"YYYYMMDDHHmm":            "200601021504",
+		"[prefix]-YYYY":           "prefix-2006",
+		"YYYY-MM-DDTHH":           "2006-01-02T15",
+		"YYYY-MM-DD HH":           "2006-01-02 15",
+		"YYYY-MM-DD hhA":          "2006-01-02 03PM",
+		"YYYY-MM-DD HH:mm:ss.SSS": "2006-01-02 15:04:05.000",
+		"ddd, MMM D YYYY":         "Mon, Jan 2 2006",
+		"dddd MMMM DD, YY h:mm a": "Monday January 02, 06 3:04 pm",
+		"YYYY-DDDD":               "2006-002",
+		"YYYY-MM-DDTHH:mm:ssZ":    "2006-01-02T15:04:05-07:00",
+		"YYYYMMDDHHmmss ZZ":       "20060102150405 -0700",
+		"L":                       "01/02/2006",
+		"[Journal] YYYY-MM-DD":    "Journal 2006-01-02",
 	}
 
 	for input, expected := range tests {
@@ -28,3 +39,107 @@ func TestMomentToGoLayoutUnbalancedLiteral(t *testing.T) {
 		t.Fatal("expected failure for unbalanced literal")
 	}
 }
+
+func TestMomentToGoLayoutWithoutEquivalent(t *testing.T) {
+	formats := []string{
+		"Do MMMM YYYY",      // ordinals
+		"YYYY-DDD",          // unpadded day of year
+		"gggg-[W]ww",        // week numbers
+		"YYYY-[Q]Q",         // quarters
+		"YYYY-MM-DD H:mm",   // unpadded 24-hour clock
+		"X",                 // unix timestamps
+		"YYYYMMDDHHmmssSSS", // fraction without a separator
+		"[Jan] YYYY",        // literal read as a month by Go
+		"YYYYMMDD[v2]",      // literal read as a day by Go
+	}
+
+	for _, format := range formats {
+		if layout, ok := momentToGoLayout(format); ok {
+			t.Errorf("expected no Go layout for %q, got %q", format, layout)
+		}
+	}
+}
+
+func TestMomentFormatter(t *testing.T) {
+	afternoon := time.Date(2025, 11, 12, 15, 34, 45, 123456789, time.UTC)
+	midnight := time.Date(2021, 1, 3, 0, 7, 9, 5000000, time.FixedZone("IST", 5*3600+30*60))
+
+	tests := []struct {
+		format    string
+		afternoon string
+		midnight  string
+	}{
+		{"YYYY YY Y YYYYY YYYYYY", "2025 25 2025 02025 +002025", "2021 21 2021 02021 +002021"},
+		{"M Mo MM MMM MMMM", "11 11th 11 Nov November", "1 1st 01 Jan January"},
+		{"Q Qo", "4 4th", "1 1st"},
+		{"D Do DD", "12 12th 12", "3 3rd 03"},
+		{"DDD DDDo DDDD", "316 316th 316", "3 3rd 003"},
+		{"d do dd ddd dddd", "3 3rd We Wed Wednesday", "0 0th Su Sun Sunday"},
+		{"e E", "3 3", "0 7"},
+		{"w wo ww gg gggg ggggg", "46 46th 46 25 2025 02025", "2 2nd 02 21 2021 02021"},
+		{"W Wo WW GG GGGG GGGGG", "46 46th 46 25 2025 02025", "53 53rd 53 20 2020 02020"},
+		{"H HH h hh k kk A a", "15 15 3 03 15 15 PM pm", "0 00 12 12 24 24 AM am"},
+		{"m mm s ss", "34 34 45 45", "7 07 9 09"},
+		{"S SS SSS SSSS SSSSSSSSS", "1 12 123 1230 123000000", "0 00 005 0050 005000000"},
+		{"Z ZZ z", "+00:00 +0000 UTC", "+05:30 +0530 "},
+		{"X x", "1762961685 1762961685123", "1609612629 1609612629005"},
+		{"Hmm hmmss", "1534 33445", "007 120709"},
+		{"LT|LTS|L|l", "3:34 PM|3:34:45 PM|11/12/2025|11/12/2025", "12:07 AM|12:07:09 AM|01/03/2021|1/3/2021"},
+		{"LL|ll", "November 12, 2025|Nov 12, 2025", "January 3, 2021|Jan 3, 2021"},
+		{"LLL", "November 12, 2025 3:34 PM", "January 3, 2021 12:07 AM"},
+		{"LLLL", "Wednesday, November 12, 2025 3:34 PM", "Sunday, January 3, 2021 12:07 AM"},
+		{"llll", "Wed, Nov 12, 2025 3:34 PM", "Sun, Jan 3, 2021 12:07 AM"},
+		{"YYYY-MM-DDTHH:mm", "2025-11-12T15:34", "2021-01-03T00:07"},
+		{"[Week] W [of] GGGG", "Week 46 of 2025", "Week 53 of 2020"},
+		{"[LT] \\Q Q \\[", "LT Q 4 [", "LT Q 1 ["},
+		{"dddd, Do [of] MMMM", "Wednesday, 12th of November", "Sunday, 3rd of January"},
+	}
+
+	for _, tt := range tests {
+		render, ok := momentFormatter(tt.format)
+		if !ok {
+			t.Fatalf("expected formatter for %q", tt.format)
+		}
+		if got := render(afternoon); got != tt.afternoon {
+			t.Errorf("%q at %v = %q, want %q", tt.format, afternoon, got, tt.afternoon)
+		}
+		if got := render(midnight); got != tt.midnight {
+			t.Errorf("%q at %v = %q, want %q", tt.format, midnight, got, tt.midnight)
+		}
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	tests := map[int]string{
+		0: "0th", 1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th",
+		21: "21st", 22: "22nd", 23: "23rd", 101: "101st", 111: "111th", 112: "112th", 366: "366th",
+	}
+
+	for n, want := range tests {
+		if got := ordinal(n); got != want {
+			t.Errorf("ordinal(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestMomentFormatterWeekTokens(t *testing.T) {
+	moment := time.Date(2025, 12, 28, 14, 5, 0, 0, time.UTC)
+
+	tests := map[string]string{
+		"gggg-[W]ww":      "2026-W01",
+		"GGGG-[W]WW":      "2025-W52",
+		"YYYY-[Q]Q":       "2025-Q4",
+		"YYYY-MM-DD HHmm": "2025-12-28 1405",
+		"[Week] W":        "Week 52",
+	}
+
+	for input, expected := range tests {
+		render, ok := momentFormatter(input)
+		if !ok {
+			t.Fatalf("expected formatter for %q", input)
+		}
+		if actual := render(moment); actual != expected {
+			t.Fatalf("expected %q -> %q, got %q", input, expected, actual)
+		}
+	}
+}
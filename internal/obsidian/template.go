@@ -0,0 +1,111 @@
+package obsidian
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TemplateData describes the note a vault template is expanded for.
+type TemplateData struct {
+	// Title is the note name without extension.
+	Title string
+	// Time is the moment the note is for.
+	Time time.Time
+	// Format is the Moment format of the note name; YYYY-MM-DD when empty.
+	Format string
+}
+
+var (
+	titlePattern     = regexp.MustCompile(`(?i){{\s*title\s*}}`)
+	timePattern      = regexp.MustCompile(`(?i){{\s*time\s*}}`)
+	datePattern      = regexp.MustCompile(`(?i){{\s*(?:date|time)\s*(?:([+-]\d+)([yqmwdhs]))?\s*(:.+?)?}}`)
+	yesterdayPattern = regexp.MustCompile(`(?i){{\s*yesterday\s*}}`)
+	tomorrowPattern  = regexp.MustCompile(`(?i){{\s*tomorrow\s*}}`)
+	weekdayPattern   = regexp.MustCompile(`(?i){{\s*(sunday|monday|tuesday|wednesday|thursday|friday|saturday)\s*:(.*?)}}`)
+)
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+// ExpandTemplate fills in the variables the Daily Notes and Periodic Notes
+// plugins support in template notes:
+//
+//	{{title}}                  note name
+//	{{time}}                   time as HH:mm
+//	{{date}}, {{date:FORMAT}}  note date in its own or the given Moment format
+//	{{date+1d:FORMAT}}         date shifted by y, q, M, w, d, h, m (minutes) or s
+//	{{yesterday}}, {{tomorrow}}
+//	{{monday:FORMAT}}          a day of the note's week, weeks starting on Sunday
+//
+// Variables are case-insensitive; anything else is left untouched.
+func ExpandTemplate(text string, data TemplateData) string {
+	format := data.Format
+	if format == "" {
+		format = "YYYY-MM-DD"
+	}
+	render := func(t time.Time, format string, original string) string {
+		fn, ok := momentFormatter(strings.TrimSpace(format))
+		if !ok {
+			return original
+		}
+		return fn(t)
+	}
+
+	text = titlePattern.ReplaceAllLiteralString(text, data.Title)
+	text = timePattern.ReplaceAllLiteralString(text, render(data.Time, "HH:mm", ""))
+	text = datePattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := datePattern.FindStringSubmatch(match)
+		t := data.Time
+		if groups[1] != "" {
+			n, _ := strconv.Atoi(groups[1])
+			t = shift(t, n, groups[2])
+		}
+		if groups[3] != "" {
+			return render(t, groups[3][1:], match)
+		}
+		return render(t, format, match)
+	})
+	text = yesterdayPattern.ReplaceAllLiteralString(text, render(data.Time.AddDate(0, 0, -1), format, ""))
+	text = tomorrowPattern.ReplaceAllLiteralString(text, render(data.Time.AddDate(0, 0, 1), format, ""))
+	return weekdayPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := weekdayPattern.FindStringSubmatch(match)
+		offset := 0
+		for i, day := range weekdays {
+			if strings.EqualFold(day, groups[1]) {
+				offset = i
+			}
+		}
+		day := data.Time.AddDate(0, 0, offset-int(data.Time.Weekday()))
+		return render(day, groups[2], match)
+	})
+}
+
+// shift adds n units to t like Moment's add, where "M" is months and "m"
+// minutes, and months are clamped to their last day.
+func shift(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "y", "Y":
+		return addMonths(t, 12*n)
+	case "q", "Q":
+		return addMonths(t, 3*n)
+	case "M":
+		return addMonths(t, n)
+	case "w", "W":
+		return t.AddDate(0, 0, 7*n)
+	case "d", "D":
+		return t.AddDate(0, 0, n)
+	case "h", "H":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "m":
+		return t.Add(time.Duration(n) * time.Minute)
+	default:
+		return t.Add(time.Duration(n) * time.Second)
+	}
+}
+
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), last)-1)
+}
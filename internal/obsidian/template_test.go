@@ -0,0 +1,65 @@
+package obsidian
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	data := TemplateData{
+		Title:  "2025-01-31",
+		Time:   time.Date(2025, 1, 31, 9, 5, 0, 0, time.UTC),
+		Format: "YYYY-MM-DD",
+	}
+
+	tests := map[string]string{
+		"# {{title}}":                         "# 2025-01-31",
+		"{{ Title }} at {{time}}":             "2025-01-31 at 09:05",
+		"{{date}}":                            "2025-01-31",
+		"{{date:dddd, MMMM Do}}":              "Friday, January 31st",
+		"{{time:h:mm A}}":                     "9:05 AM",
+		"{{date+1M:YYYY-MM-DD}}":              "2025-02-28",
+		"{{date-1y:YYYY}}":                    "2024",
+		"{{date+1q:YYYY-[Q]Q}}":               "2025-Q2",
+		"{{date+2w}}":                         "2025-02-14",
+		"{{date+30m:HH:mm}}":                  "09:35",
+		"[[{{yesterday}}]] [[{{tomorrow}}]]":  "[[2025-01-30]] [[2025-02-01]]",
+		"{{sunday:MMM D}}–{{saturday:MMM D}}": "Jan 26–Feb 1",
+		"{{Monday:YYYY-MM-DD}}":               "2025-01-27",
+		"{{unknown}} {{date:[unbalanced}}":    "{{unknown}} {{date:[unbalanced}}",
+	}
+
+	for input, want := range tests {
+		if got := ExpandTemplate(input, data); got != want {
+			t.Errorf("ExpandTemplate(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestExpandTemplateDefaultFormat(t *testing.T) {
+	data := TemplateData{Title: "Weekly", Time: time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)}
+	if got := ExpandTemplate("{{date}}", data); got != "2025-12-29" {
+		t.Fatalf("expected the Daily Notes default format, got %q", got)
+	}
+
+	data.Format = "gggg-[W]ww"
+	if got := ExpandTemplate("{{date}} {{date:GGGG-[W]WW}}", data); got != "2026-W01 2026-W01" {
+		t.Fatalf("unexpected weekly expansion: %q", got)
+	}
+}
+
+func TestNoteSettingsPaths(t *testing.T) {
+	note := NoteSettings{Folder: "/Journal/Daily/", Template: "Templates/Daily"}
+	if got := note.Path("2025-11-12.md"); got != "Journal/Daily/2025-11-12.md" {
+		t.Errorf("Path = %q", got)
+	}
+	if got := note.TemplatePath(); got != "Templates/Daily.md" {
+		t.Errorf("TemplatePath = %q", got)
+	}
+	if got := (NoteSettings{Template: "Templates/Daily.md"}).TemplatePath(); got != "Templates/Daily.md" {
+		t.Errorf("TemplatePath with extension = %q", got)
+	}
+	if got := (NoteSettings{}).Path("2025-11-12.md"); got != "2025-11-12.md" {
+		t.Errorf("Path at the vault root = %q", got)
+	}
+}
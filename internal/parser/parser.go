@@ -0,0 +1,288 @@
+// Package parser recognises note names produced by stamp and recovers the
+// data encoded in them: note type, timestamp, sequence number, prefix and
+// title.
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toto/stamp/internal/calendar"
+	"github.com/toto/stamp/internal/sequential"
+)
+
+// Result describes a parsed note name.
+type Result struct {
+	// Type is the note type, e.g. "daily", "analog" or a custom type name.
+	Type string
+	// ID is the generated part of the name, without title or extension.
+	ID    string
+	Title string
+	// Ext is the file extension, if the name carried one.
+	Ext string
+	// Time is the moment encoded in the ID; zero for sequential types. Weekly,
+	// monthly, quarterly and yearly notes resolve to the start of the period.
+	Time time.Time
+	// Prefix is the letter prefix of sequential and analog IDs.
+	Prefix string
+	// Sequence is the counter value of sequential and analog IDs; zero when the
+	// type has none.
+	Sequence int
+}
+
+type format struct {
+	typ   string
+	match func(id string) (Result, bool)
+}
+
+// Parser matches names against an ordered list of formats. Custom layouts
+// and sequences are tried before the built-in formats so that they win when
+// a name is ambiguous.
+type Parser struct {
+	loc       *time.Location
+	weekStart time.Weekday
+	custom    []format
+}
+
+var (
+	fleetingPattern  = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-F(\d{6})$`)
+	voicePattern     = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-VT(\d{6})$`)
+	analogPattern    = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2})-A(\d+)$`)
+	weeklyPattern    = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+	quarterlyPattern = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+	projectPattern   = regexp.MustCompile(`^P(\d{4,})$`)
+	seqPattern       = regexp.MustCompile(`^([A-Za-z]+)(\d+)$`)
+)
+
+// New creates a parser interpreting timestamps in loc (time.Local when nil)
+// with ISO 8601 weeks.
+func New(loc *time.Location) *Parser {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &Parser{loc: loc, weekStart: time.Monday}
+}
+
+// SetWeekStart sets the first day of the week used to resolve weekly IDs.
+func (p *Parser) SetWeekStart(start time.Weekday) {
+	p.weekStart = start
+}
+
+// AddLayout registers a Go time layout, such as one derived from an Obsidian
+// vault, for the given note type.
+func (p *Parser) AddLayout(typ, layout string) {
+	p.custom = append(p.custom, format{typ: typ, match: p.layoutMatcher(layout)})
+}
+
+// AddRenderer registers a renderer for the given note type, for vault
+// formats with no Go time layout equivalent such as weekly and quarterly
+// ones. Names match when a day of a year they mention renders to them, and
+// resolve to the first such day.
+func (p *Parser) AddRenderer(typ string, render func(time.Time) string) {
+	p.custom = append(p.custom, format{typ: typ, match: p.rendererMatcher(render)})
+}
+
+// AddSequence registers a sequential ID spec for the given note type.
+func (p *Parser) AddSequence(typ string, spec sequential.Spec) {
+	prefix := spec.Prefix
+	if prefix == "" {
+		prefix = "P"
+	}
+	p.custom = append(p.custom, format{typ: typ, match: func(id string) (Result, bool) {
+		if len(id) <= len(prefix) || !strings.EqualFold(id[:len(prefix)], prefix) {
+			return Result{}, false
+		}
+		value, ok := parseDigits(id[len(prefix):])
+		if !ok {
+			return Result{}, false
+		}
+		return Result{Prefix: id[:len(prefix)], Sequence: value}, true
+	}})
+}
+
+// Parse recognises name, which may be a path, carry a title after the ID and
+// end in a file extension.
+func (p *Parser) Parse(name string) (Result, error) {
+	stem, ext := splitExt(filepath.Base(name))
+
+	formats := append(append([]format(nil), p.custom...), p.builtins()...)
+	candidates := idCandidates(stem)
+	for _, f := range formats {
+		for _, id := range candidates {
+			res, ok := f.match(id)
+			if !ok {
+				continue
+			}
+			res.Type = f.typ
+			res.ID = id
+			res.Title = strings.TrimSpace(stem[len(id):])
+			res.Ext = ext
+			return res, nil
+		}
+	}
+	return Result{}, fmt.Errorf("unrecognised note name %q", name)
+}
+
+func (p *Parser) builtins() []format {
+	return []format{
+		{typ: "fleeting", match: p.clockMatcher(fleetingPattern)},
+		{typ: "voice", match: p.clockMatcher(voicePattern)},
+		{typ: "analog", match: func(id string) (Result, bool) {
+			m := analogPattern.FindStringSubmatch(id)
+			if m == nil {
+				return Result{}, false
+			}
+			day, err := p.parseLayout("2006-01-02", m[1])
+			value, ok := parseDigits(m[2])
+			if err != nil || !ok {
+				return Result{}, false
+			}
+			return Result{Time: day, Prefix: "A", Sequence: value}, true
+		}},
+		{typ: "default", match: p.layoutMatcher("2006-01-02-1504")},
+		{typ: "daily", match: p.layoutMatcher("2006-01-02")},
+		{typ: "weekly", match: func(id string) (Result, bool) {
+			m := weeklyPattern.FindStringSubmatch(id)
+			if m == nil {
+				return Result{}, false
+			}
+			year, _ := strconv.Atoi(m[1])
+			week, _ := strconv.Atoi(m[2])
+			start := calendar.WeekStart(year, week, p.weekStart, p.loc)
+			if y, w := calendar.Week(start, p.weekStart); y != year || w != week {
+				return Result{}, false
+			}
+			return Result{Time: start}, true
+		}},
+		{typ: "quarterly", match: func(id string) (Result, bool) {
+			m := quarterlyPattern.FindStringSubmatch(id)
+			if m == nil {
+				return Result{}, false
+			}
+			year, _ := strconv.Atoi(m[1])
+			quarter, _ := strconv.Atoi(m[2])
+			return Result{Time: time.Date(year, time.Month(3*(quarter-1)+1), 1, 0, 0, 0, 0, p.loc)}, true
+		}},
+		{typ: "monthly", match: p.layoutMatcher("2006-01")},
+		{typ: "yearly", match: p.layoutMatcher("2006")},
+		{typ: "project", match: func(id string) (Result, bool) {
+			m := projectPattern.FindStringSubmatch(id)
+			if m == nil {
+				return Result{}, false
+			}
+			value, _ := strconv.Atoi(m[1])
+			return Result{Prefix: "P", Sequence: value}, true
+		}},
+		{typ: "seq", match: func(id string) (Result, bool) {
+			m := seqPattern.FindStringSubmatch(id)
+			if m == nil {
+				return Result{}, false
+			}
+			value, ok := parseDigits(m[2])
+			if !ok {
+				return Result{}, false
+			}
+			return Result{Prefix: m[1], Sequence: value}, true
+		}},
+		{typ: "zettel", match: func(id string) (Result, bool) {
+			if _, err := sequential.ParseZettel(id); err != nil {
+				return Result{}, false
+			}
+			return Result{}, true
+		}},
+	}
+}
+
+// layoutMatcher accepts IDs that round-trip through layout, which rules out
+// the leniency of time.Parse (e.g. single-digit fields).
+func (p *Parser) layoutMatcher(layout string) func(string) (Result, bool) {
+	return func(id string) (Result, bool) {
+		t, err := p.parseLayout(layout, id)
+		if err != nil || t.Format(layout) != id {
+			return Result{}, false
+		}
+		return Result{Time: t}, true
+	}
+}
+
+// rendererMatcher renders every day of the years around each four-digit run
+// in the ID. The year before and after are tried too, since week-based years
+// and calendar years disagree at their edges.
+func (p *Parser) rendererMatcher(render func(time.Time) string) func(string) (Result, bool) {
+	return func(id string) (Result, bool) {
+		seen := map[int]bool{}
+		for i := 0; i+4 <= len(id); i++ {
+			year, ok := parseDigits(id[i : i+4])
+			if !ok || seen[year] {
+				continue
+			}
+			seen[year] = true
+			day := time.Date(year-1, time.January, 1, 0, 0, 0, 0, p.loc)
+			for end := day.AddDate(3, 0, 0); day.Before(end); day = day.AddDate(0, 0, 1) {
+				if render(day) == id {
+					return Result{Time: day}, true
+				}
+			}
+		}
+		return Result{}, false
+	}
+}
+
+// clockMatcher handles the date plus HHMMSS formats of fleeting and voice notes.
+func (p *Parser) clockMatcher(pattern *regexp.Regexp) func(string) (Result, bool) {
+	return func(id string) (Result, bool) {
+		m := pattern.FindStringSubmatch(id)
+		if m == nil {
+			return Result{}, false
+		}
+		t, err := p.parseLayout("2006-01-02 150405", m[1]+" "+m[2])
+		if err != nil {
+			return Result{}, false
+		}
+		return Result{Time: t}, true
+	}
+}
+
+func (p *Parser) parseLayout(layout, value string) (time.Time, error) {
+	return time.ParseInLocation(layout, value, p.loc)
+}
+
+// idCandidates lists the possible ID parts of stem, longest first: the whole
+// stem and every prefix ending before a space, since titles follow the ID
+// after a space while some layouts contain spaces themselves.
+func idCandidates(stem string) []string {
+	candidates := []string{stem}
+	for i := len(stem) - 1; i > 0; i-- {
+		if stem[i] == ' ' && stem[i-1] != ' ' {
+			candidates = append(candidates, stem[:i])
+		}
+	}
+	return candidates
+}
+
+// splitExt separates a file extension. Suffixes starting with a digit or
+// containing spaces (as in "21.3a7b" or "v1.2 plan") are part of the name.
+func splitExt(name string) (string, string) {
+	ext := filepath.Ext(name)
+	if len(ext) < 2 || ext == name || strings.Contains(ext, " ") || (ext[1] >= '0' && ext[1] <= '9') {
+		return name, ""
+	}
+	return strings.TrimSuffix(name, ext), ext
+}
+
+func parseDigits(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+	}
+	value, err := strconv.Atoi(s)
+	return value, err == nil
+}
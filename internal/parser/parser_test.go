@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/toto/stamp/internal/sequential"
+)
+
+func TestParse_BuiltinFormats(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("timezone data unavailable")
+	}
+	p := New(tokyo)
+
+	tests := []struct {
+		name     string
+		wantType string
+		wantID   string
+		wantTime string // RFC 3339, empty for untimed types
+		wantPre  string
+		wantSeq  int
+		wantTtl  string
+		wantExt  string
+	}{
+		{"2025-11-12-1534", "default", "2025-11-12-1534", "2025-11-12T15:34:00+09:00", "", 0, "", ""},
+		{"2025-11-12", "daily", "2025-11-12", "2025-11-12T00:00:00+09:00", "", 0, "", ""},
+		{"2025-11-12-F153045", "fleeting", "2025-11-12-F153045", "2025-11-12T15:30:45+09:00", "", 0, "", ""},
+		{"2025-11-12-VT153045.m4a", "voice", "2025-11-12-VT153045", "2025-11-12T15:30:45+09:00", "", 0, "", ".m4a"},
+		{"2025-11-12-A3.md", "analog", "2025-11-12-A3", "2025-11-12T00:00:00+09:00", "A", 3, "", ".md"},
+		{"2025-W46", "weekly", "2025-W46", "2025-11-10T00:00:00+09:00", "", 0, "", ""},
+		{"2025-Q4", "quarterly", "2025-Q4", "2025-10-01T00:00:00+09:00", "", 0, "", ""},
+		{"2025-11", "monthly", "2025-11", "2025-11-01T00:00:00+09:00", "", 0, "", ""},
+		{"2025", "yearly", "2025", "2025-01-01T00:00:00+09:00", "", 0, "", ""},
+		{"P0395 New CLI Tool", "project", "P0395", "", "P", 395, "New CLI Tool", ""},
+		{"projects/P0395 New CLI Tool.md", "project", "P0395", "", "P", 395, "New CLI Tool", ".md"},
+		{"P0396 v1.2 plan", "project", "P0396", "", "P", 396, "v1.2 plan", ""},
+		{"jin007 Interview notes.md", "seq", "jin007", "", "jin", 7, "Interview notes", ".md"},
+		{"21.3a7b Luhmann.md", "zettel", "21.3a7b", "", "", 0, "Luhmann", ".md"},
+		{"2025-11-12 Standup", "daily", "2025-11-12", "2025-11-12T00:00:00+09:00", "", 0, "Standup", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := p.Parse(tt.name)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.name, err)
+			}
+			if res.Type != tt.wantType || res.ID != tt.wantID {
+				t.Errorf("Parse(%q) = %s %q, want %s %q", tt.name, res.Type, res.ID, tt.wantType, tt.wantID)
+			}
+			gotTime := ""
+			if !res.Time.IsZero() {
+				gotTime = res.Time.Format(time.RFC3339)
+			}
+			if gotTime != tt.wantTime {
+				t.Errorf("Time = %q, want %q", gotTime, tt.wantTime)
+			}
+			if res.Prefix != tt.wantPre || res.Sequence != tt.wantSeq {
+				t.Errorf("Prefix/Sequence = %q/%d, want %q/%d", res.Prefix, res.Sequence, tt.wantPre, tt.wantSeq)
+			}
+			if res.Title != tt.wantTtl || res.Ext != tt.wantExt {
+				t.Errorf("Title/Ext = %q/%q, want %q/%q", res.Title, res.Ext, tt.wantTtl, tt.wantExt)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	p := New(time.UTC)
+
+	for _, name := range []string{
+		"",
+		"Meeting notes",
+		"2025-13-01",
+		"2025-W54",
+		"2025-Q5",
+		"2025-11-12-F1530",
+		"P03a",
+	} {
+		if res, err := p.Parse(name); err == nil {
+			t.Errorf("Parse(%q) = %s %q, want error", name, res.Type, res.ID)
+		}
+	}
+}
+
+func TestParse_WeekStart(t *testing.T) {
+	p := New(time.UTC)
+	p.SetWeekStart(time.Sunday)
+
+	res, err := p.Parse("2025-W46")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Time.Format("2006-01-02"); got != "2025-11-09" {
+		t.Errorf("week start = %s, want 2025-11-09", got)
+	}
+}
+
+func TestParse_CustomFormats(t *testing.T) {
+	p := New(time.UTC)
+	// Obsidian-derived layouts, e.g. Unique Note Creator "YYYYMMDDHHmm" and
+	// Daily Notes "YYYY-MM-DD dddd".
+	p.AddLayout("default", "200601021504")
+	p.AddLayout("daily", "2006-01-02 Monday")
+	p.AddSequence("interview", sequential.Spec{Prefix: "INT", Width: 3})
+
+	tests := []struct {
+		name, wantType, wantID, wantTitle string
+	}{
+		{"202511121534 Idea.md", "default", "202511121534", "Idea"},
+		{"2025-11-12 Wednesday.md", "daily", "2025-11-12 Wednesday", ""},
+		{"2025-11-12 Wednesday Retro", "daily", "2025-11-12 Wednesday", "Retro"},
+		{"INT012 Jane", "interview", "INT012", "Jane"},
+		{"2025-11-12", "daily", "2025-11-12", ""},
+	}
+
+	for _, tt := range tests {
+		res, err := p.Parse(tt.name)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.name, err)
+			continue
+		}
+		if res.Type != tt.wantType || res.ID != tt.wantID || res.Title != tt.wantTitle {
+			t.Errorf("Parse(%q) = %s %q %q, want %s %q %q",
+				tt.name, res.Type, res.ID, res.Title, tt.wantType, tt.wantID, tt.wantTitle)
+		}
+	}
+
+	if res, _ := p.Parse("INT012"); res.Sequence != 12 || res.Prefix != "INT" {
+		t.Errorf("interview sequence = %s%d, want INT12", res.Prefix, res.Sequence)
+	}
+}
+
+func TestParse_Renderers(t *testing.T) {
+	p := New(time.UTC)
+	// Periodic Notes formats such as "gggg-[Week] ww" and "YYYY [Q]Q", which
+	// have no Go time layout equivalent.
+	p.AddRenderer("weekly", func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-Week %02d", year, week)
+	})
+	p.AddRenderer("quarterly", func(t time.Time) string {
+		return fmt.Sprintf("%d Q%d", t.Year(), (int(t.Month())+2)/3)
+	})
+
+	tests := []struct {
+		name, wantType, wantID, wantTitle, wantTime string
+	}{
+		{"2025-Week 46.md", "weekly", "2025-Week 46", "", "2025-11-10"},
+		{"2026-Week 01 Plan", "weekly", "2026-Week 01", "Plan", "2025-12-29"},
+		{"2025 Q4 Review.md", "quarterly", "2025 Q4", "Review", "2025-10-01"},
+		{"2025-W46", "weekly", "2025-W46", "", "2025-11-10"},
+	}
+
+	for _, tt := range tests {
+		res, err := p.Parse(tt.name)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.name, err)
+			continue
+		}
+		if res.Type != tt.wantType || res.ID != tt.wantID || res.Title != tt.wantTitle {
+			t.Errorf("Parse(%q) = %s %q %q, want %s %q %q",
+				tt.name, res.Type, res.ID, res.Title, tt.wantType, tt.wantID, tt.wantTitle)
+		}
+		if got := res.Time.Format("2006-01-02"); got != tt.wantTime {
+			t.Errorf("Parse(%q) time = %s, want %s", tt.name, got, tt.wantTime)
+		}
+	}
+
+	if _, err := p.Parse("2025-Week 54"); err == nil {
+		t.Error("Parse(2025-Week 54) error = nil, want no match")
+	}
+}
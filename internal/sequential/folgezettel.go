@@ -0,0 +1,263 @@
+package sequential
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Zettel is a Luhmann-style Folgezettel ID such as "21/3a7b": a sequence of
+// segments alternating between numbers and lowercase letters. A separator
+// ("/" or ".") may follow the first segment; filenames typically use "."
+// since "/" is not allowed there. IDs that differ only in that separator are
+// considered equal.
+type Zettel struct {
+	Segments []string
+	// Sep is the separator written after the first segment, if any.
+	Sep string
+}
+
+// ParseZettel parses a Folgezettel ID.
+func ParseZettel(id string) (Zettel, error) {
+	z, rest, ok := parseZettelPrefix(id)
+	if !ok || rest != "" {
+		return Zettel{}, fmt.Errorf("invalid Folgezettel ID %q", id)
+	}
+	return z, nil
+}
+
+// String renders the ID using its separator.
+func (z Zettel) String() string {
+	if len(z.Segments) == 0 {
+		return ""
+	}
+	return z.Segments[0] + z.Sep + strings.Join(z.Segments[1:], "")
+}
+
+// key identifies a Zettel independent of the separator style.
+func (z Zettel) key() string {
+	if len(z.Segments) == 0 {
+		return ""
+	}
+	sep := ""
+	if z.Sep != "" {
+		sep = "/"
+	}
+	return z.Segments[0] + sep + strings.Join(z.Segments[1:], "")
+}
+
+func (z Zettel) parent() Zettel {
+	return Zettel{Segments: z.Segments[:len(z.Segments)-1], Sep: z.Sep}
+}
+
+func (z Zettel) child(segment string) Zettel {
+	segments := append(append([]string(nil), z.Segments...), segment)
+	return Zettel{Segments: segments, Sep: z.Sep}
+}
+
+// parseZettelPrefix reads a Folgezettel ID from the start of name, returning
+// the remainder. Names must start with a number.
+func parseZettelPrefix(name string) (Zettel, string, bool) {
+	var z Zettel
+	i := 0
+	for i < len(name) {
+		start := i
+		if isDigit(name[i]) {
+			for i < len(name) && isDigit(name[i]) {
+				i++
+			}
+		} else if isLower(name[i]) && len(z.Segments) > 0 && isNumber(z.Segments[len(z.Segments)-1]) {
+			for i < len(name) && isLower(name[i]) {
+				i++
+			}
+		} else if len(z.Segments) == 1 && z.Sep == "" && (name[i] == '/' || name[i] == '.') &&
+			i+1 < len(name) && isDigit(name[i+1]) {
+			z.Sep = name[i : i+1]
+			i++
+			continue
+		} else {
+			break
+		}
+
+		segment := name[start:i]
+		if len(z.Segments) > 0 && isNumber(segment) == isNumber(z.Segments[len(z.Segments)-1]) {
+			// Only reachable after a separator: numbers must alternate with letters,
+			// except directly after the first segment's separator.
+			if z.Sep == "" || len(z.Segments) != 1 {
+				return Zettel{}, "", false
+			}
+		}
+		if isNumber(segment) && segment[0] == '0' {
+			return Zettel{}, "", false
+		}
+		z.Segments = append(z.Segments, segment)
+	}
+
+	if len(z.Segments) == 0 {
+		return Zettel{}, "", false
+	}
+	return z, name[i:], true
+}
+
+// parseZettelName extracts the Folgezettel ID from a file or directory name
+// such as "21.3a7b Title.md". The ID must be followed by a space, a file
+// extension, or nothing.
+func parseZettelName(name string) (Zettel, bool) {
+	if ext := filepath.Ext(name); ext != "" && !strings.ContainsAny(ext, "0123456789") {
+		name = strings.TrimSuffix(name, ext)
+	}
+	z, rest, ok := parseZettelPrefix(name)
+	if !ok {
+		return Zettel{}, false
+	}
+	if rest != "" && !strings.HasPrefix(rest, " ") {
+		return Zettel{}, false
+	}
+	return z, true
+}
+
+// NextZettelSibling returns the ID following after in its train: the highest
+// existing sibling at the same level, incremented. An empty after yields the
+// next top-level number.
+func NextZettelSibling(dir, after string, scan Scan) (Zettel, error) {
+	if after == "" {
+		existing, err := collectZettels(dir, scan)
+		if err != nil {
+			return Zettel{}, err
+		}
+		return nextChildOf(Zettel{}, existing, "1"), nil
+	}
+
+	z, err := ParseZettel(after)
+	if err != nil {
+		return Zettel{}, err
+	}
+	existing, err := collectZettels(dir, scan)
+	if err != nil {
+		return Zettel{}, err
+	}
+
+	parent := z.parent()
+	next := nextChildOf(parent, existing, z.Segments[len(z.Segments)-1])
+	// nextChildOf never goes below the reference segment itself.
+	if compareSegments(next.Segments[len(next.Segments)-1], z.Segments[len(z.Segments)-1]) <= 0 {
+		next = parent.child(incrementSegment(z.Segments[len(z.Segments)-1]))
+	}
+	next.Sep = z.Sep
+	if len(next.Segments) == 1 {
+		next.Sep = ""
+	}
+	return next, nil
+}
+
+// NextZettelChild returns the first free child of branch: "1" below a letter
+// segment and "a" below a number segment, or the next one after the highest
+// existing child.
+func NextZettelChild(dir, branch string, sep string, scan Scan) (Zettel, error) {
+	z, err := ParseZettel(branch)
+	if err != nil {
+		return Zettel{}, err
+	}
+	existing, err := collectZettels(dir, scan)
+	if err != nil {
+		return Zettel{}, err
+	}
+
+	first := "a"
+	if !isNumber(z.Segments[len(z.Segments)-1]) {
+		first = "1"
+	}
+	if len(z.Segments) == 1 && z.Sep == "" && sep != "" {
+		// Luhmann started each section's train after a separator: 21/1, 21/2...
+		z.Sep = sep
+		first = "1"
+	}
+
+	next := nextChildOf(z, existing, first)
+	next.Sep = z.Sep
+	return next, nil
+}
+
+// nextChildOf returns the child of parent following the highest existing one,
+// or parent+first when there is none. Children are segments of the same kind
+// as first.
+func nextChildOf(parent Zettel, existing []Zettel, first string) Zettel {
+	depth := len(parent.Segments)
+	parentKey := parent.key()
+	highest := ""
+
+	for _, z := range existing {
+		if len(z.Segments) <= depth {
+			continue
+		}
+		candidate := Zettel{Segments: z.Segments[:depth], Sep: z.Sep}
+		if depth > 0 && candidate.key() != parentKey {
+			continue
+		}
+		if depth == 1 && (z.Sep != "") != (parent.Sep != "") {
+			continue
+		}
+		segment := z.Segments[depth]
+		if isNumber(segment) != isNumber(first) {
+			continue
+		}
+		if highest == "" || compareSegments(segment, highest) > 0 {
+			highest = segment
+		}
+	}
+
+	if highest == "" {
+		return parent.child(first)
+	}
+	return parent.child(incrementSegment(highest))
+}
+
+func collectZettels(dir string, scan Scan) ([]Zettel, error) {
+	var zettels []Zettel
+	err := walkEntries(dir, Spec{Scan: scan}, func(name string) {
+		if z, ok := parseZettelName(name); ok {
+			zettels = append(zettels, z)
+		}
+	})
+	return zettels, err
+}
+
+// incrementSegment advances a number by one or a letter run in bijective
+// base-26 (a, b, ... z, aa, ab, ...).
+func incrementSegment(segment string) string {
+	if isNumber(segment) {
+		n, _ := strconv.Atoi(segment)
+		return strconv.Itoa(n + 1)
+	}
+
+	letters := []byte(segment)
+	for i := len(letters) - 1; i >= 0; i-- {
+		if letters[i] < 'z' {
+			letters[i]++
+			return string(letters)
+		}
+		letters[i] = 'a'
+	}
+	return "a" + string(letters)
+}
+
+// compareSegments orders segments of the same kind.
+func compareSegments(a, b string) int {
+	if isNumber(a) {
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return x - y
+	}
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+func isLower(c byte) bool { return c >= 'a' && c <= 'z' }
+
+func isNumber(segment string) bool {
+	return segment != "" && isDigit(segment[0])
+}
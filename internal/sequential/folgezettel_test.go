@@ -0,0 +1,130 @@
+package sequential
+
+import (
+	"testing"
+)
+
+func TestParseZettel(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+		sep   string
+	}{
+		{"1", []string{"1"}, ""},
+		{"1a2b", []string{"1", "a", "2", "b"}, ""},
+		{"21/3a7b", []string{"21", "3", "a", "7", "b"}, "/"},
+		{"21.3a7b", []string{"21", "3", "a", "7", "b"}, "."},
+		{"12aa3", []string{"12", "aa", "3"}, ""},
+	}
+
+	for _, tt := range tests {
+		z, err := ParseZettel(tt.input)
+		if err != nil {
+			t.Fatalf("ParseZettel(%q) error = %v", tt.input, err)
+		}
+		if len(z.Segments) != len(tt.want) || z.Sep != tt.sep {
+			t.Fatalf("ParseZettel(%q) = %+v, want %v sep %q", tt.input, z, tt.want, tt.sep)
+		}
+		for i := range tt.want {
+			if z.Segments[i] != tt.want[i] {
+				t.Fatalf("ParseZettel(%q) = %+v, want %v", tt.input, z, tt.want)
+			}
+		}
+		if z.String() != tt.input {
+			t.Fatalf("String() = %q, want %q", z.String(), tt.input)
+		}
+	}
+
+	for _, invalid := range []string{"", "a1", "1A", "01", "1//2", "2025-11-12", "1a/2"} {
+		if _, err := ParseZettel(invalid); err == nil {
+			t.Errorf("ParseZettel(%q) expected error", invalid)
+		}
+	}
+}
+
+func TestIncrementSegment(t *testing.T) {
+	tests := map[string]string{
+		"1":  "2",
+		"9":  "10",
+		"a":  "b",
+		"z":  "aa",
+		"az": "ba",
+		"zz": "aaa",
+	}
+	for input, want := range tests {
+		if got := incrementSegment(input); got != want {
+			t.Errorf("incrementSegment(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestNextZettelExclude(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, []string{"1 Root.md", "2.md", "2025.md"})
+
+	scan := Scan{Exclude: func(name string) bool { return name == "2025.md" }}
+	got, err := NextZettelSibling(dir, "", scan)
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if got.String() != "3" {
+		t.Errorf("got %q, want 3 with 2025.md excluded", got.String())
+	}
+}
+
+func TestNextZettel(t *testing.T) {
+	dir := t.TempDir()
+	makeTree(t, dir, []string{
+		"1 Root.md",
+		"1a First branch.md",
+		"1a1.md",
+		"1a2 Second.md",
+		"1b.md",
+		"2.md",
+		"21.3a7b Deep.md",
+		"21.3a7c.md",
+		"2025-11-12.md",
+		"notes.md",
+	})
+
+	tests := []struct {
+		name   string
+		after  string
+		branch string
+		sep    string
+		want   string
+	}{
+		{"top level", "", "", "", "22"},
+		{"sibling of letter", "1a", "", "", "1c"},
+		{"sibling of number", "1a1", "", "", "1a3"},
+		{"sibling with separator", "21/3a7b", "", "", "21/3a7d"},
+		{"sibling matches file separator", "21.3a7b", "", "", "21.3a7d"},
+		{"sibling without existing", "5x", "", "", "5y"},
+		{"first child of letter", "", "1b", "", "1b1"},
+		{"next child of number", "", "1", "", "1c"},
+		{"next child of letter", "", "1a", "", "1a3"},
+		{"child of leaf", "", "21/3a7c", "", "21/3a7c1"},
+		{"section child with separator", "", "21", "/", "21/4"},
+		{"section child new section", "", "7", ".", "7.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got Zettel
+				err error
+			)
+			if tt.branch != "" {
+				got, err = NextZettelChild(dir, tt.branch, tt.sep, Scan{})
+			} else {
+				got, err = NextZettelSibling(dir, tt.after, Scan{})
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Fatalf("got %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package sequential
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ReservedSuffix is appended to placeholder files that hold a reserved ID.
+// Placeholders start with the formatted code, so Highest counts them like any
+// other entry and concurrent scans never hand out a reserved number.
+const ReservedSuffix = ".stamp-reserved"
+
+const maxReserveAttempts = 100
+
+// Reservation is a sequential ID claimed by an exclusively created placeholder
+// file. It must be either materialised or released.
+type Reservation struct {
+	Code  string
+	Value int
+
+	dir         string
+	placeholder string
+}
+
+// Reserve atomically claims the next sequential ID in dir.
+//
+// The placeholder "<code>.stamp-reserved" is created with O_EXCL, and the
+// directory is re-scanned afterwards: if another entry with the same number
+// appeared in the meantime (for example a note materialised by a concurrent
+// process), the placeholder is dropped and the next number is tried.
+func Reserve(dir string, spec Spec) (*Reservation, error) {
+	return ReserveFrom(dir, spec, nil)
+}
+
+// ReserveFrom is Reserve with the number chosen by issue, which is given the
+// next number found in dir and returns the one to claim, at least as large,
+// e.g. from a shared counter. A nil issue claims the number found.
+func ReserveFrom(dir string, spec Spec, issue func(next int) (int, error)) (*Reservation, error) {
+	spec = spec.normalized()
+
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		highest, err := Highest(dir, spec)
+		if err != nil {
+			return nil, err
+		}
+
+		value := spec.Start
+		if highest >= spec.Start {
+			value = highest + 1
+		}
+		if issue != nil {
+			if value, err = issue(value); err != nil {
+				return nil, err
+			}
+		}
+		code := Format(spec, value)
+		placeholder := filepath.Join(dir, code+ReservedSuffix)
+
+		f, err := os.OpenFile(placeholder, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			if errors.Is(err, os.ErrExist) {
+				continue
+			}
+			return nil, err
+		}
+		fmt.Fprintf(f, "reserved by pid %d\n", os.Getpid())
+		if err := f.Close(); err != nil {
+			os.Remove(placeholder)
+			return nil, err
+		}
+
+		conflict, err := hasOtherEntry(dir, spec, value, filepath.Base(placeholder))
+		if err != nil {
+			os.Remove(placeholder)
+			return nil, err
+		}
+		if conflict {
+			os.Remove(placeholder)
+			continue
+		}
+
+		return &Reservation{
+			Code:        code,
+			Value:       value,
+			dir:         dir,
+			placeholder: placeholder,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("could not reserve a %s ID in %s after %d attempts", spec.Prefix, dir, maxReserveAttempts)
+}
+
+func hasOtherEntry(dir string, spec Spec, value int, own string) (bool, error) {
+	found := false
+	err := walkEntries(dir, spec, func(name string) {
+		if name == own {
+			return
+		}
+		if v, ok := parseName(name, spec); ok && v == value {
+			found = true
+		}
+	})
+	return found, err
+}
+
+// Materialize creates the file name (relative to the reservation directory)
+// with content and removes the placeholder. It fails without touching anything
+// if name already exists.
+func (r *Reservation) Materialize(name string, content []byte) (string, error) {
+	path := filepath.Join(r.dir, name)
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("refusing to overwrite existing file %s", path)
+		}
+		return "", err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	return path, r.Release()
+}
+
+// MaterializeDir creates the directory name (relative to the reservation
+// directory) and removes the placeholder.
+func (r *Reservation) MaterializeDir(name string) (string, error) {
+	path := filepath.Join(r.dir, name)
+	if err := os.Mkdir(path, 0o755); err != nil {
+		return "", err
+	}
+	return path, r.Release()
+}
+
+// Release gives up the reservation by removing its placeholder.
+func (r *Reservation) Release() error {
+	if err := os.Remove(r.placeholder); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
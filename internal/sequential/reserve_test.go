@@ -0,0 +1,211 @@
+package sequential
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReserveAndMaterialize(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "P0007 Existing.md"), nil, 0o644); err != nil {
+		t.Fatalf("setup error: %v", err)
+	}
+
+	first, err := Reserve(dir, Spec{Prefix: "P", Width: 4})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if first.Code != "P0008" {
+		t.Fatalf("Reserve() code = %s, want P0008", first.Code)
+	}
+
+	// A pending reservation is visible to plain scans.
+	code, _, err := Next(dir, Spec{Prefix: "P", Width: 4})
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if code != "P0009" {
+		t.Fatalf("Next() with pending reservation = %s, want P0009", code)
+	}
+
+	second, err := Reserve(dir, Spec{Prefix: "P", Width: 4})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if second.Code != "P0009" {
+		t.Fatalf("second Reserve() code = %s, want P0009", second.Code)
+	}
+
+	path, err := first.Materialize("P0008 Title.md", []byte("body"))
+	if err != nil {
+		t.Fatalf("Materialize() error = %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "body" {
+		t.Fatalf("materialised file = %q, %v", data, err)
+	}
+
+	if _, err := second.MaterializeDir("P0009 Folder"); err != nil {
+		t.Fatalf("MaterializeDir() error = %v", err)
+	}
+
+	placeholders, _ := filepath.Glob(filepath.Join(dir, "*"+ReservedSuffix))
+	if len(placeholders) != 0 {
+		t.Fatalf("placeholders left behind: %v", placeholders)
+	}
+}
+
+func TestReserveRelease(t *testing.T) {
+	dir := t.TempDir()
+
+	reservation, err := Reserve(dir, Spec{Prefix: "X", Width: 2})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if err := reservation.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+
+	again, err := Reserve(dir, Spec{Prefix: "X", Width: 2})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if again.Code != "X01" {
+		t.Fatalf("Reserve() after release = %s, want X01", again.Code)
+	}
+}
+
+func TestReserveFrom(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "P0012 Existing.md"), nil, 0o644); err != nil {
+		t.Fatalf("setup error: %v", err)
+	}
+
+	var asked []int
+	shared := 20 // numbers other machines took
+	issue := func(next int) (int, error) {
+		asked = append(asked, next)
+		shared = max(shared, next-1) + 1
+		return shared, nil
+	}
+
+	reservation, err := ReserveFrom(dir, Spec{Prefix: "P", Width: 4}, issue)
+	if err != nil {
+		t.Fatalf("ReserveFrom() error = %v", err)
+	}
+	if reservation.Code != "P0021" {
+		t.Errorf("ReserveFrom() code = %s, want P0021 from the shared counter", reservation.Code)
+	}
+
+	// A counter behind the directory is moved past it.
+	shared = 3
+	reservation, err = ReserveFrom(dir, Spec{Prefix: "P", Width: 4}, issue)
+	if err != nil {
+		t.Fatalf("ReserveFrom() error = %v", err)
+	}
+	if reservation.Code != "P0022" {
+		t.Errorf("ReserveFrom() code = %s, want P0022", reservation.Code)
+	}
+	if len(asked) != 2 || asked[0] != 13 || asked[1] != 22 {
+		t.Errorf("issue called with %v, want [13 22]", asked)
+	}
+
+	failing := func(int) (int, error) { return 0, fmt.Errorf("server says no") }
+	if _, err := ReserveFrom(dir, Spec{Prefix: "P", Width: 4}, failing); err == nil {
+		t.Error("ReserveFrom() error = nil, want the issue error")
+	}
+}
+
+func TestMaterializeRefusesOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "taken.md"), []byte("keep"), 0o644); err != nil {
+		t.Fatalf("setup error: %v", err)
+	}
+
+	reservation, err := Reserve(dir, Spec{})
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if _, err := reservation.Materialize("taken.md", []byte("new")); err == nil {
+		t.Fatal("expected Materialize() to refuse overwriting")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "taken.md")); string(data) != "keep" {
+		t.Fatalf("existing file was modified: %q", data)
+	}
+}
+
+// TestHelperProcess is not a real test. It is executed as a subprocess by
+// TestReserveConcurrentProcesses to reserve IDs from another process.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("STAMP_SEQ_HELPER") != "1" {
+		return
+	}
+
+	dir := os.Getenv("STAMP_SEQ_DIR")
+	for i := 0; i < 5; i++ {
+		reservation, err := Reserve(dir, Spec{Prefix: "P", Width: 4})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Reserve() error = %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := reservation.Materialize(reservation.Code+" note.md", nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Materialize() error = %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(reservation.Code)
+	}
+	os.Exit(0)
+}
+
+func TestReserveConcurrentProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping multi-process test in short mode")
+	}
+
+	dir := t.TempDir()
+	const processes = 10
+
+	var wg sync.WaitGroup
+	outputs := make([][]byte, processes)
+	errs := make([]error, processes)
+	for i := 0; i < processes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+			cmd.Env = append(os.Environ(), "STAMP_SEQ_HELPER=1", "STAMP_SEQ_DIR="+dir)
+			cmd.Stderr = os.Stderr
+			outputs[i], errs[i] = cmd.Output()
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for i := 0; i < processes; i++ {
+		if errs[i] != nil {
+			t.Fatalf("helper process %d failed: %v", i, errs[i])
+		}
+		for _, code := range strings.Fields(string(outputs[i])) {
+			if seen[code] {
+				t.Fatalf("duplicate ID reserved: %s", code)
+			}
+			seen[code] = true
+		}
+	}
+
+	if len(seen) != processes*5 {
+		t.Fatalf("reserved %d IDs, want %d", len(seen), processes*5)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != processes*5 {
+		t.Fatalf("directory has %d entries, want %d", len(entries), processes*5)
+	}
+}
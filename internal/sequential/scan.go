@@ -0,0 +1,131 @@
+package sequential
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Scan controls which directories are searched for existing IDs in addition
+// to the primary directory passed to Highest, Next and Reserve.
+type Scan struct {
+	// Dirs are extra directories to search. Relative paths are resolved
+	// against the primary directory; missing directories are skipped.
+	Dirs []string
+	// Recursive descends into subdirectories.
+	Recursive bool
+	// MaxDepth limits how many subdirectory levels are descended when
+	// Recursive is set. Zero means unlimited.
+	MaxDepth int
+	// Ignore holds glob patterns matched against entry names and slash
+	// separated paths relative to the scanned root. Matching entries are
+	// neither counted nor descended into.
+	Ignore []string
+	// Exclude reports names that are counted by no ID even though they look
+	// like one, such as a yearly note 2025.md among Folgezettel.
+	Exclude func(name string) bool
+}
+
+// walkEntries calls fn with the name of every entry visible under the scan
+// rooted at dir. Hidden directories (such as .git or .obsidian) are skipped
+// during recursive scans.
+func walkEntries(dir string, spec Spec, fn func(name string)) error {
+	if exclude := spec.Scan.Exclude; exclude != nil {
+		visit := fn
+		fn = func(name string) {
+			if !exclude(name) {
+				visit(name)
+			}
+		}
+	}
+
+	if err := walkRoot(dir, spec.Scan, fn); err != nil {
+		return err
+	}
+
+	for _, extra := range spec.Scan.Dirs {
+		root := extra
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(dir, root)
+		}
+		if filepath.Clean(root) == filepath.Clean(dir) {
+			continue
+		}
+		if err := walkRoot(root, spec.Scan, fn); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func walkRoot(root string, scan Scan, fn func(name string)) error {
+	if !scan.Recursive {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !scan.ignored(entry.Name(), entry.Name()) {
+				fn(entry.Name())
+			}
+		}
+		return nil
+	}
+
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// Unreadable subdirectories should not abort the whole scan.
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path == root {
+			return nil
+		}
+
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return relErr
+		}
+		rel = filepath.ToSlash(rel)
+
+		if scan.ignored(entry.Name(), rel) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+
+		fn(entry.Name())
+
+		if entry.IsDir() && scan.MaxDepth > 0 && strings.Count(rel, "/")+1 > scan.MaxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+func (s Scan) ignored(name, rel string) bool {
+	for _, pattern := range s.Ignore {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package sequential
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func makeTree(t *testing.T, root string, paths []string) {
+	t.Helper()
+	for _, p := range paths {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("mkdir error: %v", err)
+		}
+		if err := os.WriteFile(full, nil, 0o644); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+	}
+}
+
+func TestHighestScan(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, []string{
+		"P0003 Current.md",
+		"Archive/P0010 Old.md",
+		"Archive/2024/P0020 Older.md",
+		"Archive/2024/deep/P0030 Deepest.md",
+		"node_modules/P0900.md",
+		".git/P0999",
+	})
+
+	tests := []struct {
+		name string
+		scan Scan
+		want int
+	}{
+		{"flat", Scan{}, 3},
+		{"recursive", Scan{Recursive: true, Ignore: []string{"node_modules"}}, 30},
+		{"depth one", Scan{Recursive: true, MaxDepth: 1, Ignore: []string{"node_modules"}}, 10},
+		{"depth two", Scan{Recursive: true, MaxDepth: 2, Ignore: []string{"node_modules"}}, 20},
+		{"ignore relative path", Scan{Recursive: true, Ignore: []string{"node_modules", "Archive/2024"}}, 10},
+		{"recursive without ignore", Scan{Recursive: true}, 900},
+		{"extra dir", Scan{Dirs: []string{"Archive"}}, 10},
+		{"missing extra dir", Scan{Dirs: []string{"Missing"}}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			highest, err := Highest(root, Spec{Prefix: "P", Width: 4, Scan: tt.scan})
+			if err != nil {
+				t.Fatalf("Highest() error = %v", err)
+			}
+			if highest != tt.want {
+				t.Fatalf("Highest() = %d, want %d", highest, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextScansFromSubfolder(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, []string{
+		"Projects/P0041 Active.md",
+		"Archive/P0040 Done.md",
+	})
+
+	archive := filepath.Join(root, "Archive")
+	code, _, err := Next(filepath.Join(root, "Projects"), Spec{Prefix: "P", Width: 4, Scan: Scan{Dirs: []string{archive}}})
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if code != "P0042" {
+		t.Fatalf("Next() = %s, want P0042", code)
+	}
+}
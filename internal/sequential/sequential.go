@@ -2,7 +2,6 @@ package sequential
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 )
@@ -12,6 +11,7 @@ type Spec struct {
 	Prefix string
 	Width  int
 	Start  int
+	Scan   Scan
 }
 
 func (s Spec) normalized() Spec {
@@ -28,24 +28,23 @@ func (s Spec) normalized() Spec {
 	return normalized
 }
 
-// Highest returns the highest numeric component that matches the spec in dir.
+// Highest returns the highest numeric component that matches the spec in dir
+// and in any additional locations described by spec.Scan.
 func Highest(dir string, spec Spec) (int, error) {
 	spec = spec.normalized()
 
-	entries, err := os.ReadDir(dir)
-	if err != nil {
-		return 0, err
-	}
-
 	maxValue := 0
-	for _, entry := range entries {
-		value, ok := parseName(entry.Name(), spec)
+	err := walkEntries(dir, spec, func(name string) {
+		value, ok := parseName(name, spec)
 		if !ok {
-			continue
+			return
 		}
 		if value > maxValue {
 			maxValue = value
 		}
+	})
+	if err != nil {
+		return 0, err
 	}
 
 	return maxValue, nil